@@ -1,24 +1,42 @@
 package main
 
 import (
+    "bufio"
+    "bytes"
     "encoding/json"
     "flag"
     "fmt"
     "html/template"
+    "io"
     "io/ioutil"
     "log"
+    "math"
     "net/http"
     "os"
     "path/filepath"
     "regexp"
+    "sort"
+    "strconv"
     "strings"
     "sync"
     "time"
 
     "github.com/fsnotify/fsnotify"
+    "github.com/getkin/kin-openapi/openapi3"
     "github.com/gorilla/mux"
+    "github.com/hashicorp/hcl"
+    "github.com/jhump/protoreflect/desc"
+    "github.com/jhump/protoreflect/desc/protoparse"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
     "github.com/santhosh-tekuri/jsonschema/v5"
     "github.com/spf13/pflag"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/reflect/protoreflect"
+    "google.golang.org/protobuf/types/dynamicpb"
+    "gopkg.in/yaml.v3"
 )
 
 const (
@@ -35,10 +53,45 @@ var (
     maxUploadSizeMB    int
     maxUploadSize      int64
     defaultOutputLevel string
-    cache              = make(map[string]*jsonschema.Schema)
+    extensionsDir      string
+    bulkWorkers        int
+    maxBulkSize        int
+    openapiDir         string
+    defaultCleanNulls  bool
+    projectTokensFile  string
+    projectTokens      = make(map[string]string)
+    allowRemoteRefs    bool
+    refLoader          = newRefResolver()
+    openapiDocs        = make(map[string]*openapiDoc)
+    openapiMutex       sync.RWMutex
+    cache              = make(map[string]map[int]*jsonschema.Schema)
+    currentVersion     = make(map[string]int)
     cacheMutex         sync.RWMutex
+    schemaRegistry     = newSchemaRegistry()
     stats              = make(map[string]*PathStats)
     statsMutex         sync.Mutex
+    extensions         = newExtensionRegistry()
+    validationsTotal   = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "youvalidateme_validations_total",
+        Help: "Total number of document validations, labeled by schema and result.",
+    }, []string{"schema", "result"})
+    schemaLoadErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "youvalidateme_schema_load_errors_total",
+        Help: "Total number of schema load errors, labeled by schema.",
+    }, []string{"schema"})
+    uploadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "youvalidateme_uploads_total",
+        Help: "Total number of schema uploads, labeled by result.",
+    }, []string{"result"})
+    validationDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name: "youvalidateme_validation_duration_seconds",
+        Help: "Time spent validating a single document against a schema.",
+    })
+    payloadSizeBytes = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "youvalidateme_payload_size_bytes",
+        Help:    "Size in bytes of validated document payloads.",
+        Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+    })
     showVersion        bool
     validSpecs         = map[string]*jsonschema.Draft{
         "draft4":    jsonschema.Draft4,
@@ -73,6 +126,13 @@ func init() {
     pflag.StringVar(&defaultSpec, "default-spec", "draft7", "Default JSON Schema spec version (default: draft7)")
     pflag.IntVar(&maxUploadSizeMB, "max-upload-size", 2, "Maximum upload size in megabytes (valid range: 1-100)")
     pflag.StringVar(&defaultOutputLevel, "default-outputlevel", "basic", "Default output level (valid values: basic, flag, detailed, verbose)")
+    pflag.StringVar(&extensionsDir, "extensions-dir", "", "Directory of extension config files registering custom keywords/formats (default: disabled)")
+    pflag.IntVar(&bulkWorkers, "bulk-workers", 4, "Number of concurrent workers for bulk NDJSON validation (default: 4)")
+    pflag.IntVar(&maxBulkSize, "max-bulk-size", 10000, "Maximum number of lines accepted per bulk NDJSON request (default: 10000)")
+    pflag.StringVar(&openapiDir, "openapi-dir", "", "Directory of OpenAPI 3.x documents to load for request/response validation (default: disabled)")
+    pflag.BoolVar(&defaultCleanNulls, "clean-nulls", false, "Default for whether /validate strips null-valued keys before validation, overridable per-request with ?clean= (default: false)")
+    pflag.StringVar(&projectTokensFile, "project-tokens-file", "", "JSON file mapping project name to required access token for /projects/{project}/... routes (default: disabled, no access control)")
+    pflag.BoolVar(&allowRemoteRefs, "allow-remote-refs", false, "Allow schema $refs to be fetched over http(s) (default: false, only local $refs are resolved)")
     pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
     pflag.Usage = printHelp
 }
@@ -94,6 +154,195 @@ func safePath(base, name string) (string, error) {
     return fullPath, nil
 }
 
+// ExtensionRegistry holds custom JSON Schema keywords and string formats that
+// are registered at startup and applied to every compiler instance, cached or
+// inline, so operators can encode business rules JSON Schema cannot express.
+//
+// This is a narrower take than a general plugin system: a keyword/format is
+// always a single regular expression matched against a string value, loaded
+// from static JSON config rather than a Go plugin (.so) or an embedded
+// JS/CEL expression evaluator. Running operator-supplied code or
+// expressions in-process is a much bigger trust boundary than matching a
+// compiled regex, so this trades some flexibility for not needing to
+// sandbox arbitrary logic.
+type ExtensionRegistry struct {
+    mu       sync.RWMutex
+    formats  []string
+    keywords []string
+}
+
+func newExtensionRegistry() *ExtensionRegistry {
+    return &ExtensionRegistry{}
+}
+
+// extensionConfig describes a single custom keyword or format as loaded from
+// a JSON file in --extensions-dir. A keyword/format is a regular expression
+// applied to the string value of the instance being validated.
+type extensionConfig struct {
+    Keyword string `json:"keyword"`
+    Format  string `json:"format"`
+    Pattern string `json:"pattern"`
+}
+
+// keywordExtSchema implements jsonschema.ExtSchema for a pattern-backed
+// custom keyword such as x-luhn or x-country.
+type keywordExtSchema struct {
+    name string
+    re   *regexp.Regexp
+}
+
+func (k *keywordExtSchema) Validate(ctx jsonschema.ValidationContext, v interface{}) error {
+    s, ok := v.(string)
+    if !ok {
+        return nil
+    }
+    if !k.re.MatchString(s) {
+        return ctx.Error(k.name, "value does not satisfy %s", k.name)
+    }
+    return nil
+}
+
+// keywordExtCompiler implements jsonschema.ExtCompiler for a single
+// pattern-backed custom keyword, compiling it into a keywordExtSchema only
+// when that keyword is actually present on the schema object being compiled.
+type keywordExtCompiler struct {
+    name string
+    re   *regexp.Regexp
+}
+
+func (c *keywordExtCompiler) Compile(ctx jsonschema.CompilerContext, m map[string]interface{}) (jsonschema.ExtSchema, error) {
+    if _, ok := m[c.name]; !ok {
+        return nil, nil
+    }
+    return &keywordExtSchema{name: c.name, re: c.re}, nil
+}
+
+func registerKeywordExtension(compiler *jsonschema.Compiler, name string, re *regexp.Regexp) {
+    compiler.RegisterExtension(name, jsonschema.MustCompileString(name+"-meta.json", `{
+        "properties": {
+            "`+name+`": true
+        }
+    }`), &keywordExtCompiler{name: name, re: re})
+}
+
+// applyExtensions wires every registered custom keyword onto a freshly
+// created compiler. Custom formats are registered once, globally, against
+// jsonschema.Formats and need no per-compiler wiring.
+//
+// AssertFormat is only turned on when at least one custom format or keyword
+// is actually registered. Per draft2019/draft2020 semantics, "format" is
+// annotation-only unless a compiler opts into asserting it - forcing that on
+// unconditionally would turn every "format" keyword into a hard validation
+// failure for every schema server-wide, not just the ones using a custom
+// extension, which --extensions-dir being unset should never do.
+func applyExtensions(compiler *jsonschema.Compiler, keywordPatterns map[string]*regexp.Regexp) {
+    extensions.mu.RLock()
+    hasCustomFormats := len(extensions.formats) > 0
+    extensions.mu.RUnlock()
+    if len(keywordPatterns) > 0 || hasCustomFormats {
+        compiler.AssertFormat = true
+    }
+    for name, re := range keywordPatterns {
+        registerKeywordExtension(compiler, name, re)
+    }
+}
+
+var keywordPatterns = make(map[string]*regexp.Regexp)
+
+// loadExtensions reads every JSON config file in dir and registers the
+// custom keywords/formats it describes. It is called once at startup; an
+// empty extensionsDir disables the registry entirely.
+func loadExtensions(dir string) error {
+    if dir == "" {
+        return nil
+    }
+    files, err := ioutil.ReadDir(dir)
+    if err != nil {
+        return fmt.Errorf("failed to read extensions directory: %w", err)
+    }
+    for _, file := range files {
+        if filepath.Ext(file.Name()) != ".json" {
+            continue
+        }
+        path := filepath.Join(dir, file.Name())
+        data, err := ioutil.ReadFile(path)
+        if err != nil {
+            return fmt.Errorf("failed to read extension config %s: %w", path, err)
+        }
+        var cfg extensionConfig
+        if err := json.Unmarshal(data, &cfg); err != nil {
+            return fmt.Errorf("invalid extension config %s: %w", path, err)
+        }
+        re, err := regexp.Compile(cfg.Pattern)
+        if err != nil {
+            return fmt.Errorf("invalid pattern in extension config %s: %w", path, err)
+        }
+        extensions.mu.Lock()
+        if cfg.Format != "" {
+            jsonschema.Formats[cfg.Format] = func(v interface{}) bool {
+                s, ok := v.(string)
+                if !ok {
+                    return true
+                }
+                return re.MatchString(s)
+            }
+            extensions.formats = append(extensions.formats, cfg.Format)
+        }
+        if cfg.Keyword != "" {
+            keywordPatterns[cfg.Keyword] = re
+            extensions.keywords = append(extensions.keywords, cfg.Keyword)
+        }
+        extensions.mu.Unlock()
+        log.Printf("Registered extension from %s", path)
+    }
+    return nil
+}
+
+// loadProjectTokens reads a JSON object mapping project name to required
+// access token from path and installs it as projectTokens. A project with no
+// entry here is unrestricted, so the default (empty) config leaves every
+// project, including the legacy default one, open exactly as before this
+// feature existed. Called once at startup; an empty path disables the check
+// entirely.
+func loadProjectTokens(path string) error {
+    if path == "" {
+        return nil
+    }
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return fmt.Errorf("failed to read project tokens file: %w", err)
+    }
+    var tokens map[string]string
+    if err := json.Unmarshal(data, &tokens); err != nil {
+        return fmt.Errorf("invalid project tokens file %s: %w", path, err)
+    }
+    projectTokens = tokens
+    return nil
+}
+
+// checkProjectAccess reports whether r is authorized to act on project. A
+// project absent from projectTokens is unrestricted; one present requires an
+// exact match on the X-Project-Token header, so a misconfigured or missing
+// token fails closed rather than silently falling back to open access.
+func checkProjectAccess(r *http.Request, project string) bool {
+    token, restricted := projectTokens[project]
+    if !restricted {
+        return true
+    }
+    return token != "" && r.Header.Get("X-Project-Token") == token
+}
+
+func extensionsHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    extensions.mu.RLock()
+    defer extensions.mu.RUnlock()
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "keywords": extensions.keywords,
+        "formats":  extensions.formats,
+    })
+    logRequest(r, "Extensions listed")
+}
+
 func getSpec(r *http.Request) (*jsonschema.Draft, error) {
     specParam := r.URL.Query().Get("spec")
     if specParam == "" {
@@ -106,21 +355,267 @@ func getSpec(r *http.Request) (*jsonschema.Draft, error) {
     return spec, nil
 }
 
-func loadSchema(path string) (*jsonschema.Schema, error) {
-    if filepath.Ext(path) != ".json" {
-        return nil, fmt.Errorf("file extension must be .json: %s", path)
+// supportedDocFormats maps the file extensions and Content-Types this server
+// accepts for both schemas and validated documents to a canonical format
+// name ("json", "yaml", "hcl").
+var supportedDocFormats = map[string]string{
+    ".json":             "json",
+    ".yaml":             "yaml",
+    ".yml":              "yaml",
+    ".hcl":              "hcl",
+    "application/json":  "json",
+    "application/yaml":  "yaml",
+    "application/x-yaml": "yaml",
+    "application/hcl":   "hcl",
+}
+
+// docFormatFromRequest resolves the format of the request body: an explicit
+// ?format= query parameter wins, then the Content-Type header, defaulting to
+// JSON so existing clients are unaffected.
+func docFormatFromRequest(r *http.Request) (string, error) {
+    if f := r.URL.Query().Get("format"); f != "" {
+        format, ok := supportedDocFormats["."+f]
+        if !ok {
+            format, ok = supportedDocFormats[f]
+        }
+        if !ok {
+            return "", fmt.Errorf("unsupported format: %s", f)
+        }
+        return format, nil
+    }
+    if ct := r.Header.Get("Content-Type"); ct != "" {
+        if format, ok := supportedDocFormats[ct]; ok {
+            return format, nil
+        }
+    }
+    return "json", nil
+}
+
+// decodeDocument parses data as the given format and returns it as a plain
+// Go value tree (maps, slices, scalars) usable by jsonschema.Schema.Validate
+// or json.Marshal, regardless of the original syntax.
+func decodeDocument(data []byte, format string) (interface{}, error) {
+    switch format {
+    case "yaml":
+        var v interface{}
+        if err := yaml.Unmarshal(data, &v); err != nil {
+            return nil, fmt.Errorf("invalid YAML: %w", err)
+        }
+        return v, nil
+    case "hcl":
+        var v interface{}
+        if err := hcl.Unmarshal(data, &v); err != nil {
+            return nil, fmt.Errorf("invalid HCL: %w", err)
+        }
+        return v, nil
+    default:
+        var v interface{}
+        if err := json.Unmarshal(data, &v); err != nil {
+            return nil, fmt.Errorf("invalid JSON: %w", err)
+        }
+        return v, nil
+    }
+}
+
+// formatFromExt maps a schema/document file extension to its canonical
+// format name, defaulting to "json" for anything unrecognized.
+func formatFromExt(path string) string {
+    if format, ok := supportedDocFormats[filepath.Ext(path)]; ok {
+        return format
+    }
+    return "json"
+}
+
+// schemaDraftURIs maps a schema's "$schema" value (without any trailing
+// fragment marker) to the matching jsonschema.Draft and the stable short
+// name SchemaRegistry uses as its cache key.
+var schemaDraftURIs = map[string]struct {
+    draft *jsonschema.Draft
+    name  string
+}{
+    "http://json-schema.org/draft-04/schema":       {jsonschema.Draft4, "draft4"},
+    "http://json-schema.org/draft-06/schema":       {jsonschema.Draft6, "draft6"},
+    "http://json-schema.org/draft-07/schema":       {jsonschema.Draft7, "draft7"},
+    "https://json-schema.org/draft/2019-09/schema": {jsonschema.Draft2019, "draft2019"},
+    "https://json-schema.org/draft/2020-12/schema": {jsonschema.Draft2020, "draft2020"},
+}
+
+// detectDraft resolves which draft to compile path's schema against: the
+// file's own "$schema" keyword takes precedence, then specOverride (e.g. a
+// request's ?spec= parameter), then the server's --default-spec.
+func detectDraft(path string, specOverride string) (draftName string, draft *jsonschema.Draft, err error) {
+    if content, readErr := ioutil.ReadFile(path); readErr == nil {
+        if formatFromExt(path) != "json" {
+            if decoded, decErr := decodeDocument(content, formatFromExt(path)); decErr == nil {
+                if asJSON, marshalErr := json.Marshal(decoded); marshalErr == nil {
+                    content = asJSON
+                }
+            }
+        }
+        var probe struct {
+            Schema string `json:"$schema"`
+        }
+        if json.Unmarshal(content, &probe) == nil && probe.Schema != "" {
+            if entry, ok := schemaDraftURIs[strings.TrimSuffix(probe.Schema, "#")]; ok {
+                return entry.name, entry.draft, nil
+            }
+        }
+    }
+
+    specName := specOverride
+    if specName == "" {
+        specName = defaultSpec
+    }
+    draft, ok := validSpecs[specName]
+    if !ok {
+        return "", nil, fmt.Errorf("invalid spec: %s", specName)
+    }
+    return specName, draft, nil
+}
+
+// refResolver backs jsonschema.Compiler's LoadURL extension point so "$ref"
+// targets that point outside the schema doing the referencing (e.g.
+// {"$ref": "global.json#/definitions/Email"}) can be resolved against files
+// stored alongside it on disk, and optionally over http(s) when
+// --allow-remote-refs is set. Loaded documents are cached by URL, and a URL
+// currently being loaded is tracked so a $ref cycle surfaces as a clear
+// error instead of recursing forever.
+type refResolver struct {
+    mu      sync.Mutex
+    cache   map[string][]byte
+    loading map[string]bool
+}
+
+func newRefResolver() *refResolver {
+    return &refResolver{cache: make(map[string][]byte), loading: make(map[string]bool)}
+}
+
+// LoadURL implements the func(string) (io.ReadCloser, error) signature of
+// jsonschema.Compiler.LoadURL, resolving url (as computed by the compiler
+// from the referencing schema's base URI) to its raw bytes.
+func (rr *refResolver) LoadURL(url string) (io.ReadCloser, error) {
+    rr.mu.Lock()
+    if data, ok := rr.cache[url]; ok {
+        rr.mu.Unlock()
+        return ioutil.NopCloser(bytes.NewReader(data)), nil
+    }
+    if rr.loading[url] {
+        rr.mu.Unlock()
+        return nil, fmt.Errorf("circular $ref detected resolving %s", url)
+    }
+    rr.loading[url] = true
+    rr.mu.Unlock()
+    defer func() {
+        rr.mu.Lock()
+        delete(rr.loading, url)
+        rr.mu.Unlock()
+    }()
+
+    data, err := rr.fetch(url)
+    if err != nil {
+        return nil, fmt.Errorf("failed to resolve $ref %q: %w", url, err)
+    }
+
+    rr.mu.Lock()
+    rr.cache[url] = data
+    rr.mu.Unlock()
+    return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// loadDocument resolves url the same way LoadURL does, but decodes it into a
+// JSON document rather than a byte stream, for callers (bundleSchema) that
+// need to walk the result rather than hand it back to the compiler.
+func (rr *refResolver) loadDocument(url string) (interface{}, error) {
+    rc, err := rr.LoadURL(url)
+    if err != nil {
+        return nil, err
+    }
+    defer rc.Close()
+    data, err := ioutil.ReadAll(rc)
+    if err != nil {
+        return nil, err
+    }
+    var doc interface{}
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("$ref %q is not valid JSON: %w", url, err)
+    }
+    return doc, nil
+}
+
+// fetch reads the raw bytes behind a resolved $ref URL. Local paths are
+// tried next to the referencing schema first (the path the jsonschema
+// compiler already resolved the $ref to), then fall back to the owning
+// project's schema directory, so one global.json at the project root can be
+// shared by every schema (and every version of every schema) in that
+// project.
+func (rr *refResolver) fetch(url string) ([]byte, error) {
+    if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+        if !allowRemoteRefs {
+            return nil, fmt.Errorf("remote $refs are disabled (enable with --allow-remote-refs)")
+        }
+        resp, err := http.Get(url)
+        if err != nil {
+            return nil, err
+        }
+        defer resp.Body.Close()
+        if resp.StatusCode != http.StatusOK {
+            return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, url)
+        }
+        return ioutil.ReadAll(resp.Body)
+    }
+
+    path := strings.TrimPrefix(url, "file://")
+    if data, err := ioutil.ReadFile(path); err == nil {
+        return data, nil
+    }
+    project, _ := projectAndNameForDir(filepath.Dir(path))
+    fallback := filepath.Join(projectDir(project), filepath.Base(path))
+    data, err := ioutil.ReadFile(fallback)
+    if err != nil {
+        return nil, fmt.Errorf("no such file: %s", filepath.Base(path))
+    }
+    return data, nil
+}
+
+// newSchemaCompiler builds a *jsonschema.Compiler the way the stored-schema
+// compile sites (loadSchema, schema upload, /infer) need it: draft pinned,
+// annotations extracted, custom
+// keyword/format extensions applied, and $ref resolution routed through
+// refLoader so stored schemas can reference sibling files.
+func newSchemaCompiler(draft *jsonschema.Draft) *jsonschema.Compiler {
+    compiler := jsonschema.NewCompiler()
+    compiler.Draft = draft
+    compiler.ExtractAnnotations = true
+    applyExtensions(compiler, keywordPatterns)
+    compiler.LoadURL = refLoader.LoadURL
+    return compiler
+}
+
+func loadSchema(path string, draft *jsonschema.Draft) (*jsonschema.Schema, error) {
+    format := formatFromExt(path)
+    if _, ok := supportedDocFormats[filepath.Ext(path)]; !ok {
+        return nil, fmt.Errorf("unsupported schema file extension: %s", path)
     }
     log.Printf("Validating schema %s against meta schema", path)
 
-    // Read schema content using Go's file reading functions
+    // Read schema content and normalize YAML/HCL into JSON before compiling,
+    // since jsonschema.Compiler only understands JSON text.
     schemaContent, err := ioutil.ReadFile(path)
     if err != nil {
         return nil, fmt.Errorf("failed to read schema file: %w", err)
     }
+    if format != "json" {
+        decoded, err := decodeDocument(schemaContent, format)
+        if err != nil {
+            return nil, err
+        }
+        schemaContent, err = json.Marshal(decoded)
+        if err != nil {
+            return nil, fmt.Errorf("failed to convert %s schema to JSON: %w", format, err)
+        }
+    }
 
-    compiler := jsonschema.NewCompiler()
-    compiler.Draft = validSpecs[defaultSpec]
-    compiler.ExtractAnnotations = true
+    compiler := newSchemaCompiler(draft)
     if err := compiler.AddResource(path, strings.NewReader(string(schemaContent))); err != nil {
         return nil, err
     }
@@ -134,273 +629,2227 @@ func loadSchema(path string) (*jsonschema.Schema, error) {
     return schema, nil
 }
 
-func loadSchemas() {
-    files, err := ioutil.ReadDir(schemasDir)
-    if err != nil {
-        log.Fatalf("Failed to read schemas directory: %v", err)
-    }
+// SchemaRegistry memoizes compiled schema validators keyed by (on-disk path,
+// draft name), so a request asking for a non-default draft via ?spec=
+// doesn't recompile the schema on every call. watchSchemas invalidates a
+// path's entries whenever the underlying file changes.
+type SchemaRegistry struct {
+    mu      sync.RWMutex
+    entries map[schemaRegistryKey]*jsonschema.Schema
+}
 
-    for _, file := range files {
-        if filepath.Ext(file.Name()) == ".json" {
-            schemaPath := filepath.Join(schemasDir, file.Name())
-            schema, err := loadSchema(schemaPath)
-            if err != nil {
-                log.Printf("Failed to load schema %s: %v", schemaPath, err)
-                continue
-            }
-            cacheMutex.Lock()
-            cache[file.Name()] = schema
-            cacheMutex.Unlock()
+type schemaRegistryKey struct {
+    path  string
+    draft string
+}
+
+func newSchemaRegistry() *SchemaRegistry {
+    return &SchemaRegistry{entries: make(map[schemaRegistryKey]*jsonschema.Schema)}
+}
+
+func (reg *SchemaRegistry) get(path string, draftName string) (*jsonschema.Schema, bool) {
+    reg.mu.RLock()
+    defer reg.mu.RUnlock()
+    schema, ok := reg.entries[schemaRegistryKey{path, draftName}]
+    return schema, ok
+}
+
+func (reg *SchemaRegistry) set(path string, draftName string, schema *jsonschema.Schema) {
+    reg.mu.Lock()
+    defer reg.mu.Unlock()
+    reg.entries[schemaRegistryKey{path, draftName}] = schema
+}
+
+// invalidate drops every compiled draft variant cached for path, forcing the
+// next lookup to recompile from the (changed) file on disk.
+func (reg *SchemaRegistry) invalidate(path string) {
+    reg.mu.Lock()
+    defer reg.mu.Unlock()
+    for key := range reg.entries {
+        if key.path == path {
+            delete(reg.entries, key)
         }
     }
 }
 
-func watchSchemas() error {
-    watcher, err := fsnotify.NewWatcher()
+// resolveStoredSchema compiles (or returns a memoized compile of) name's
+// version under a request-specified draft override, without disturbing the
+// default-draft schema already warmed in cache by loadSchemaVersion.
+func resolveStoredSchema(project, name string, version int, specOverride string) (*jsonschema.Schema, error) {
+    path, err := findSchemaVersionPath(project, name, version)
     if err != nil {
-        return fmt.Errorf("failed to create watcher: %v", err)
+        return nil, err
     }
-    defer watcher.Close()
-
-    err = watcher.Add(schemasDir)
+    draftName, draft, err := detectDraft(path, specOverride)
     if err != nil {
-        return fmt.Errorf("failed to add directory to watcher: %v", err)
+        return nil, err
+    }
+    if schema, ok := schemaRegistry.get(path, draftName); ok {
+        return schema, nil
+    }
+    schema, err := loadSchema(path, draft)
+    if err != nil {
+        return nil, err
     }
+    schemaRegistry.set(path, draftName, schema)
+    return schema, nil
+}
 
-    for {
-        select {
-        case event := <-watcher.Events:
-            if event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create {
-                schemaPath := event.Name
-                if filepath.Ext(schemaPath) == ".json" {
-                    schema, err := loadSchema(schemaPath)
-                    if err != nil {
-                        log.Printf("Failed to reload schema %s: %v", schemaPath, err)
-                        continue
-                    }
-                    cacheMutex.Lock()
-                    cache[filepath.Base(schemaPath)] = schema
-                    cacheMutex.Unlock()
-                    log.Printf("Reloaded schema: %s", schemaPath)
-                }
-            }
-        case err := <-watcher.Errors:
-            log.Println("Error watching schemas:", err)
-        }
+// Each schema name has its own directory under schemasDir holding one file
+// per version (v<N>.json) plus a manifest.json recording which version is
+// current. This lets operators publish a new version and roll back to an
+// older one without losing history.
+const schemaManifestFile = "manifest.json"
+
+type schemaManifest struct {
+    Current int `json:"current"`
+}
+
+// defaultProject is the implicit project backing the legacy, unscoped
+// /schema/{name} and /validate/{name} routes, so existing clients keep
+// working unchanged after schemas gained project/tenant scoping.
+const defaultProject = "default"
+
+// projectsRootDir holds every non-default project as a subdirectory, keeping
+// them out of schemasDir's own namespace (which is the default project's
+// schema directories) so a project can never collide with a schema name.
+func projectsRootDir() string {
+    return filepath.Join(schemasDir, "projects")
+}
+
+func projectDir(project string) string {
+    if project == "" || project == defaultProject {
+        return schemasDir
     }
+    return filepath.Join(projectsRootDir(), project)
 }
 
-func logRequest(r *http.Request, outcome string) {
-    if verbose {
-        log.Printf("[%s] %s %s - %s", time.Now().Format(time.RFC3339), r.Method, r.URL.Path, outcome)
+// schemaKey is the cache/currentVersion map key for (project, name). The
+// default project keeps the bare name as its key so metrics, stats, and
+// on-disk layout for existing deployments are unaffected by this feature.
+func schemaKey(project, name string) string {
+    if project == "" || project == defaultProject {
+        return name
     }
+    return project + "/" + name
 }
 
-func stripFilePathsFromErrors(validationErrors []jsonschema.BasicError) []string {
-    var errors []string
-    for _, ve := range validationErrors {
-        errorMsg := ve.KeywordLocation + " " + ve.InstanceLocation
-        if strings.HasPrefix(errorMsg, "file://"+workingDir) {
-            errorMsg = strings.Replace(errorMsg, "file://"+workingDir, "file://", 1)
+// projectAndNameForDir recovers the (project, name) a schema's on-disk
+// directory belongs to, used by watchSchemas to resolve fsnotify events that
+// fire for both default-project and namespaced schema directories.
+func projectAndNameForDir(dir string) (project, name string) {
+    name = filepath.Base(dir)
+    parent := filepath.Dir(dir)
+    absParent, parentErr := filepath.Abs(parent)
+    absRoot, rootErr := filepath.Abs(schemasDir)
+    if parentErr == nil && rootErr == nil && absParent == absRoot {
+        return defaultProject, name
+    }
+    return filepath.Base(parent), name
+}
+
+func schemaNameDir(project, name string) string {
+    return filepath.Join(projectDir(project), name)
+}
+
+// schemaVersionFileRe matches a version file's base name in any of the
+// supported schema syntaxes (v<N>.json, v<N>.yaml, v<N>.yml, v<N>.hcl).
+var schemaVersionFileRe = regexp.MustCompile(`^v(\d+)\.(json|yaml|yml|hcl)$`)
+
+func schemaVersionPath(project, name string, version int, ext string) string {
+    return filepath.Join(schemaNameDir(project, name), fmt.Sprintf("v%d.%s", version, ext))
+}
+
+// findSchemaVersionPath locates the on-disk file for a published version,
+// regardless of which syntax (JSON/YAML/HCL) it was uploaded in.
+func findSchemaVersionPath(project, name string, version int) (string, error) {
+    entries, err := ioutil.ReadDir(schemaNameDir(project, name))
+    if err != nil {
+        return "", err
+    }
+    prefix := fmt.Sprintf("v%d.", version)
+    for _, entry := range entries {
+        if strings.HasPrefix(entry.Name(), prefix) && schemaVersionFileRe.MatchString(entry.Name()) {
+            return filepath.Join(schemaNameDir(project, name), entry.Name()), nil
         }
-        errors = append(errors, errorMsg)
     }
-    return errors
+    return "", fmt.Errorf("version %d not found for schema %s", version, name)
 }
 
-func validateHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "application/json")
-    vars := mux.Vars(r)
-    schemaFile := vars["schema"]
-    if filepath.Ext(schemaFile) != ".json" {
-        schemaFile += ".json"
+func schemaManifestPath(project, name string) string {
+    return filepath.Join(schemaNameDir(project, name), schemaManifestFile)
+}
+
+func readSchemaManifest(project, name string) (int, error) {
+    data, err := ioutil.ReadFile(schemaManifestPath(project, name))
+    if err != nil {
+        return 0, err
+    }
+    var manifest schemaManifest
+    if err := json.Unmarshal(data, &manifest); err != nil {
+        return 0, fmt.Errorf("invalid manifest for schema %s: %w", name, err)
     }
+    return manifest.Current, nil
+}
 
-    cacheMutex.RLock()
-    schema, found := cache[schemaFile]
-    cacheMutex.RUnlock()
+// writeSchemaManifest publishes the given version as current atomically by
+// writing a temp file and renaming it over the manifest, so watchSchemas
+// never observes a half-written manifest.
+func writeSchemaManifest(project, name string, version int) error {
+    data, err := json.MarshalIndent(schemaManifest{Current: version}, "", "  ")
+    if err != nil {
+        return err
+    }
+    tmpPath := schemaManifestPath(project, name) + ".tmp"
+    if err := ioutil.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+        return err
+    }
+    return os.Rename(tmpPath, schemaManifestPath(project, name))
+}
 
-    if !found {
-        http.Error(w, `{"error":"Schema not found"}`, http.StatusNotFound)
-        logRequest(r, "Schema not found")
-        return
+// writeSchemaVersion publishes version as a new file atomically (temp file +
+// rename), so a reader never sees a partially-written schema.
+func writeSchemaVersion(project, name string, version int, ext string, content []byte) error {
+    path := schemaVersionPath(project, name, version, ext)
+    tmpPath := path + ".tmp"
+    if err := ioutil.WriteFile(tmpPath, content, 0644); err != nil {
+        return err
     }
+    return os.Rename(tmpPath, path)
+}
 
-    body, err := ioutil.ReadAll(r.Body)
+// listSchemaVersions returns the sorted version numbers found for a schema
+// name by scanning its directory for v<N>.<json|yaml|yml|hcl> files.
+func listSchemaVersions(project, name string) ([]int, error) {
+    entries, err := ioutil.ReadDir(schemaNameDir(project, name))
     if err != nil {
-        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
-        logRequest(r, "Invalid request body")
-        return
+        return nil, err
+    }
+    var versions []int
+    for _, entry := range entries {
+        m := schemaVersionFileRe.FindStringSubmatch(entry.Name())
+        if m == nil {
+            continue
+        }
+        var n int
+        fmt.Sscanf(m[1], "%d", &n)
+        versions = append(versions, n)
     }
+    sort.Ints(versions)
+    return versions, nil
+}
 
-    var jsonData interface{}
-    if err := json.Unmarshal(body, &jsonData); err != nil {
-        http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
-        logRequest(r, "Invalid JSON")
+func loadSchemaVersion(project, name string, version int) error {
+    path, err := findSchemaVersionPath(project, name, version)
+    if err != nil {
+        return err
+    }
+    schemaRegistry.invalidate(path)
+    draftName, draft, err := detectDraft(path, "")
+    if err != nil {
+        return err
+    }
+    schema, err := loadSchema(path, draft)
+    if err != nil {
+        return err
+    }
+    key := schemaKey(project, name)
+    cacheMutex.Lock()
+    if cache[key] == nil {
+        cache[key] = make(map[int]*jsonschema.Schema)
+    }
+    cache[key][version] = schema
+    cacheMutex.Unlock()
+    schemaRegistry.set(path, draftName, schema)
+    return nil
+}
+
+// loadSchemasForProject loads every schema name found directly under
+// project's directory, mirroring the original loadSchemas body for a single
+// project so it can be reused for the default project and every namespaced
+// one discovered under projectsRootDir.
+func loadSchemasForProject(project string) {
+    entries, err := ioutil.ReadDir(projectDir(project))
+    if err != nil {
+        log.Printf("Failed to read schemas directory for project %s: %v", project, err)
         return
     }
 
-    err = schema.Validate(jsonData)
-    if err != nil {
-        updateStats(r.URL.Path, false)
-        validationErrors := err.(*jsonschema.ValidationError).BasicOutput().Errors
-        errors := stripFilePathsFromErrors(validationErrors)
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Validation failed", "errors": errors})
-        logRequest(r, "Validation failed")
-        return
+    for _, entry := range entries {
+        if !entry.IsDir() {
+            continue
+        }
+        name := entry.Name()
+        versions, err := listSchemaVersions(project, name)
+        if err != nil {
+            log.Printf("Failed to list versions for schema %s: %v", name, err)
+            schemaLoadErrorsTotal.WithLabelValues(schemaKey(project, name)).Inc()
+            continue
+        }
+        for _, version := range versions {
+            if err := loadSchemaVersion(project, name, version); err != nil {
+                log.Printf("Failed to load schema %s version %d: %v", name, version, err)
+                schemaLoadErrorsTotal.WithLabelValues(schemaKey(project, name)).Inc()
+            }
+        }
+        if len(versions) == 0 {
+            continue
+        }
+        current, err := readSchemaManifest(project, name)
+        if err != nil {
+            current = versions[len(versions)-1]
+        }
+        cacheMutex.Lock()
+        currentVersion[schemaKey(project, name)] = current
+        cacheMutex.Unlock()
+    }
+}
+
+// loadSchemas loads the default project's schemas directly under schemasDir,
+// then every namespaced project found under projectsRootDir.
+func loadSchemas() {
+    if _, err := ioutil.ReadDir(schemasDir); err != nil {
+        log.Fatalf("Failed to read schemas directory: %v", err)
+    }
+    loadSchemasForProject(defaultProject)
+
+    projectEntries, err := ioutil.ReadDir(projectsRootDir())
+    if err != nil {
+        if !os.IsNotExist(err) {
+            log.Printf("Failed to read projects directory: %v", err)
+        }
+        return
+    }
+    for _, entry := range projectEntries {
+        if entry.IsDir() {
+            loadSchemasForProject(entry.Name())
+        }
+    }
+}
+
+func watchSchemas() error {
+    watcher, err := fsnotify.NewWatcher()
+    if err != nil {
+        return fmt.Errorf("failed to create watcher: %v", err)
+    }
+    defer watcher.Close()
+
+    if err := watcher.Add(schemasDir); err != nil {
+        return fmt.Errorf("failed to add directory to watcher: %v", err)
+    }
+    entries, err := ioutil.ReadDir(schemasDir)
+    if err != nil {
+        return fmt.Errorf("failed to read schemas directory: %v", err)
+    }
+    for _, entry := range entries {
+        if entry.IsDir() {
+            watcher.Add(schemaNameDir(defaultProject, entry.Name()))
+        }
+    }
+
+    // Also watch every existing project's own directory and its schema name
+    // directories; watchSchemas' Create handling below extends this to any
+    // project or schema directory added later.
+    if projectEntries, err := ioutil.ReadDir(projectsRootDir()); err == nil {
+        watcher.Add(projectsRootDir())
+        for _, projectEntry := range projectEntries {
+            if !projectEntry.IsDir() {
+                continue
+            }
+            projectPath := filepath.Join(projectsRootDir(), projectEntry.Name())
+            watcher.Add(projectPath)
+            if nameEntries, err := ioutil.ReadDir(projectPath); err == nil {
+                for _, nameEntry := range nameEntries {
+                    if nameEntry.IsDir() {
+                        watcher.Add(filepath.Join(projectPath, nameEntry.Name()))
+                    }
+                }
+            }
+        }
+    }
+
+    versionRe := schemaVersionFileRe
+    for {
+        select {
+        case event := <-watcher.Events:
+            if event.Op&fsnotify.Create == fsnotify.Create {
+                if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+                    watcher.Add(event.Name)
+                    continue
+                }
+            }
+            if event.Op&fsnotify.Write != fsnotify.Write && event.Op&fsnotify.Create != fsnotify.Create {
+                continue
+            }
+            project, name := projectAndNameForDir(filepath.Dir(event.Name))
+            base := filepath.Base(event.Name)
+            key := schemaKey(project, name)
+            switch {
+            case base == schemaManifestFile:
+                current, err := readSchemaManifest(project, name)
+                if err != nil {
+                    log.Printf("Failed to reload manifest for schema %s: %v", key, err)
+                    continue
+                }
+                cacheMutex.Lock()
+                currentVersion[key] = current
+                cacheMutex.Unlock()
+                log.Printf("Reloaded current version for schema %s: v%d", key, current)
+            case versionRe.MatchString(base):
+                var version int
+                fmt.Sscanf(versionRe.FindStringSubmatch(base)[1], "%d", &version)
+                if err := loadSchemaVersion(project, name, version); err != nil {
+                    log.Printf("Failed to reload schema %s version %d: %v", key, version, err)
+                    schemaLoadErrorsTotal.WithLabelValues(key).Inc()
+                    continue
+                }
+                log.Printf("Reloaded schema: %s v%d", key, version)
+            }
+        case err := <-watcher.Errors:
+            log.Println("Error watching schemas:", err)
+        }
+    }
+}
+
+func logRequest(r *http.Request, outcome string) {
+    if verbose {
+        log.Printf("[%s] %s %s - %s", time.Now().Format(time.RFC3339), r.Method, r.URL.Path, outcome)
+    }
+}
+
+func stripFilePathsFromErrors(validationErrors []jsonschema.BasicError) []string {
+    var errors []string
+    for _, ve := range validationErrors {
+        errorMsg := ve.KeywordLocation + " " + ve.InstanceLocation
+        if strings.HasPrefix(errorMsg, "file://"+workingDir) {
+            errorMsg = strings.Replace(errorMsg, "file://"+workingDir, "file://", 1)
+        }
+        errors = append(errors, errorMsg)
+    }
+    return errors
+}
+
+// normalizeSchemaName strips a trailing ".json" from a URL path segment so
+// callers may address a schema as either "foo" or "foo.json".
+func normalizeSchemaName(schemaFile string) string {
+    return strings.TrimSuffix(schemaFile, ".json")
+}
+
+// requestProject resolves the {project} mux var, defaulting to defaultProject
+// for the legacy unscoped routes that don't declare one.
+func requestProject(r *http.Request) string {
+    if project := mux.Vars(r)["project"]; project != "" {
+        return project
+    }
+    return defaultProject
+}
+
+// lookupSchema resolves the compiled schema for (project, name), honoring an
+// optional ?version=N query parameter and falling back to the schema's
+// current published version otherwise.
+func lookupSchema(project, name string, r *http.Request) (schema *jsonschema.Schema, version int, found bool, err error) {
+    versionParam := r.URL.Query().Get("version")
+    key := schemaKey(project, name)
+
+    cacheMutex.RLock()
+    defer cacheMutex.RUnlock()
+
+    versions, ok := cache[key]
+    if !ok {
+        return nil, 0, false, nil
+    }
+
+    if versionParam == "" {
+        version, ok = currentVersion[key]
+        if !ok {
+            return nil, 0, false, nil
+        }
+    } else {
+        version, err = strconv.Atoi(versionParam)
+        if err != nil {
+            return nil, 0, false, fmt.Errorf("invalid version: %s", versionParam)
+        }
+    }
+
+    schema, ok = versions[version]
+    return schema, version, ok, nil
+}
+
+// getOutputLevel resolves the `outputlevel` query parameter against
+// validOutputLevels, falling back to defaultOutputLevel when absent.
+func getOutputLevel(r *http.Request) (string, error) {
+    level := r.URL.Query().Get("outputlevel")
+    if level == "" {
+        level = defaultOutputLevel
+    }
+    if _, ok := validOutputLevels[level]; !ok {
+        return "", fmt.Errorf("invalid outputlevel: %s", level)
+    }
+    return level, nil
+}
+
+// cleanRequested resolves whether null-stripping is active for this request,
+// honoring the ?clean= query parameter and falling back to --clean-nulls.
+func cleanRequested(r *http.Request) (bool, error) {
+    param := r.URL.Query().Get("clean")
+    if param == "" {
+        return defaultCleanNulls, nil
+    }
+    clean, err := strconv.ParseBool(param)
+    if err != nil {
+        return false, fmt.Errorf("invalid clean: %s", param)
+    }
+    return clean, nil
+}
+
+// stripNullValues recursively removes object keys whose value is JSON null,
+// recursing into nested objects and array elements, so clients that send
+// sparse payloads with explicit nulls don't trip required/type constraints
+// meant for absent fields.
+func stripNullValues(v interface{}) interface{} {
+    switch val := v.(type) {
+    case map[string]interface{}:
+        cleaned := make(map[string]interface{}, len(val))
+        for k, child := range val {
+            if child == nil {
+                continue
+            }
+            cleaned[k] = stripNullValues(child)
+        }
+        return cleaned
+    case []interface{}:
+        cleaned := make([]interface{}, len(val))
+        for i, child := range val {
+            cleaned[i] = stripNullValues(child)
+        }
+        return cleaned
+    default:
+        return v
+    }
+}
+
+// verboseOutput extends the standard detailed output with the offending
+// instance value, the schema fragment that was applied, and any collected
+// annotations, per the `verbose` output level.
+type verboseOutput struct {
+    jsonschema.Detailed
+    Instance    interface{}            `json:"instance,omitempty"`
+    Schema      interface{}            `json:"appliedSchema,omitempty"`
+    Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+func buildVerboseOutput(instance interface{}, schema *jsonschema.Schema, valErr *jsonschema.ValidationError) verboseOutput {
+    out := verboseOutput{
+        Detailed: valErr.DetailedOutput(),
+        Instance: instance,
+    }
+    if schema != nil {
+        out.Schema = originalSchemaDocument(schema)
+        out.Annotations = schemaAnnotations(schema)
+    }
+    return out
+}
+
+// originalSchemaDocument re-reads and parses the on-disk JSON that schema was
+// compiled from, for the `appliedSchema` field of the verbose output level.
+// schema itself is the compiled *jsonschema.Schema - marshaling it directly
+// would serialize the compiler's internal representation (MinContains,
+// ContainsEval, RecursiveAnchor, DynamicRef, ...) instead of the schema an
+// operator actually authored. schema.Location carries the file:// URL it was
+// compiled from (see stripFilePathsFromErrors for the same convention); when
+// it isn't a real file on disk (the inline validate-with-schema endpoint
+// compiles from an in-memory "inline" resource, not a file), the field is
+// simply omitted rather than guessed at.
+func originalSchemaDocument(schema *jsonschema.Schema) interface{} {
+    path := strings.TrimPrefix(schema.Location, "file://")
+    if i := strings.IndexByte(path, '#'); i >= 0 {
+        path = path[:i]
+    }
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil
+    }
+    var doc interface{}
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil
+    }
+    return doc
+}
+
+// schemaAnnotations collects schema's extracted annotation keywords (title,
+// description, etc. - populated only because every compiler whose schema
+// reaches writeValidationResult sets ExtractAnnotations) into a map for the `verbose` output level.
+// jsonschema.Schema has no generic annotations collection to range over;
+// each annotation keyword is its own typed field.
+func schemaAnnotations(schema *jsonschema.Schema) map[string]interface{} {
+    annotations := make(map[string]interface{})
+    if schema.Title != "" {
+        annotations["title"] = schema.Title
+    }
+    if schema.Description != "" {
+        annotations["description"] = schema.Description
+    }
+    if schema.Comment != "" {
+        annotations["$comment"] = schema.Comment
+    }
+    if schema.Default != nil {
+        annotations["default"] = schema.Default
+    }
+    if len(schema.Examples) > 0 {
+        annotations["examples"] = schema.Examples
+    }
+    if schema.ReadOnly {
+        annotations["readOnly"] = true
+    }
+    if schema.WriteOnly {
+        annotations["writeOnly"] = true
+    }
+    if schema.Deprecated {
+        annotations["deprecated"] = true
+    }
+    if len(annotations) == 0 {
+        return nil
+    }
+    return annotations
+}
+
+// writeValidationResult writes a validation outcome shaped by the resolved
+// output level: `flag` returns only a boolean, `basic` the legacy shape,
+// `detailed` the hierarchical jsonschema output, and `verbose` that plus the
+// offending instance, applied schema fragment, and annotations. When
+// includeDocument is set, the basic-shaped responses also echo back instance
+// as "document" (used by the clean=true null-stripping mode so callers can
+// see what was removed).
+func writeValidationResult(w http.ResponseWriter, r *http.Request, level string, schemaName string, instance interface{}, schema *jsonschema.Schema, valErr error, includeDocument bool) {
+    if valErr == nil {
+        recordValidation(r, schemaName, true)
+        w.WriteHeader(http.StatusOK)
+        if level == "flag" {
+            json.NewEncoder(w).Encode(map[string]bool{"valid": true})
+        } else {
+            resp := map[string]interface{}{"result": "Validation passed"}
+            if includeDocument {
+                resp["document"] = instance
+            }
+            json.NewEncoder(w).Encode(resp)
+        }
+        logRequest(r, "Validation passed")
+        return
+    }
+
+    recordValidation(r, schemaName, false)
+    validationError, ok := valErr.(*jsonschema.ValidationError)
+    w.WriteHeader(http.StatusBadRequest)
+    switch {
+    case level == "flag":
+        json.NewEncoder(w).Encode(map[string]bool{"valid": false})
+    case level == "detailed" && ok:
+        json.NewEncoder(w).Encode(validationError.DetailedOutput())
+    case level == "verbose" && ok:
+        json.NewEncoder(w).Encode(buildVerboseOutput(instance, schema, validationError))
+    default:
+        var errors []string
+        if ok {
+            errors = stripFilePathsFromErrors(validationError.BasicOutput().Errors)
+        } else {
+            errors = []string{valErr.Error()}
+        }
+        resp := map[string]interface{}{"result": "Validation failed", "errors": errors}
+        if includeDocument {
+            resp["document"] = instance
+        }
+        json.NewEncoder(w).Encode(resp)
+    }
+    logRequest(r, "Validation failed")
+}
+
+func validateHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    vars := mux.Vars(r)
+    schemaName := normalizeSchemaName(vars["schema"])
+    project := requestProject(r)
+    if !checkProjectAccess(r, project) {
+        http.Error(w, `{"error":"Invalid or missing project token"}`, http.StatusForbidden)
+        logRequest(r, "Invalid or missing project token")
+        return
+    }
+
+    level, err := getOutputLevel(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid output level")
+        return
+    }
+
+    schema, version, found, err := lookupSchema(project, schemaName, r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid version")
+        return
+    }
+    if !found {
+        http.Error(w, `{"error":"Schema not found"}`, http.StatusNotFound)
+        logRequest(r, "Schema not found")
+        return
+    }
+
+    if specParam := r.URL.Query().Get("spec"); specParam != "" {
+        schema, err = resolveStoredSchema(project, schemaName, version, specParam)
+        if err != nil {
+            http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+            logRequest(r, "Invalid spec")
+            return
+        }
+    }
+
+    docFormat, err := docFormatFromRequest(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid format")
+        return
+    }
+
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
+        return
+    }
+
+    jsonData, err := decodeDocument(body, docFormat)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid document")
+        return
+    }
+
+    clean, err := cleanRequested(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid clean parameter")
+        return
+    }
+    if clean {
+        jsonData = stripNullValues(jsonData)
+    }
+
+    payloadSizeBytes.Observe(float64(len(body)))
+    start := time.Now()
+    err = schema.Validate(jsonData)
+    validationDuration.Observe(time.Since(start).Seconds())
+    writeValidationResult(w, r, level, schemaKey(project, schemaName), jsonData, schema, err, clean)
+}
+
+func validateWithSchemaHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+
+    spec, err := getSpec(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid spec")
+        return
+    }
+
+    level, err := getOutputLevel(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid output level")
+        return
+    }
+
+    docFormat, err := docFormatFromRequest(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid format")
+        return
+    }
+
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
+        return
+    }
+
+    envelope, err := decodeDocument(body, docFormat)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid document")
+        return
+    }
+    envelopeJSON, err := json.Marshal(envelope)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
+        return
+    }
+
+    var requestData struct {
+        Data   interface{}            `json:"data"`
+        Schema map[string]interface{} `json:"schema"`
+    }
+
+    if err := json.Unmarshal(envelopeJSON, &requestData); err != nil {
+        http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid JSON")
+        return
+    }
+
+    schemaBytes, err := json.Marshal(requestData.Schema)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid schema"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid schema")
+        return
+    }
+
+    compiler := jsonschema.NewCompiler()
+    compiler.Draft = spec
+    compiler.ExtractAnnotations = true
+    applyExtensions(compiler, keywordPatterns)
+    if err := compiler.AddResource("inline", strings.NewReader(string(schemaBytes))); err != nil {
+        http.Error(w, `{"error":"Error during schema validation"}`, http.StatusInternalServerError)
+        logRequest(r, "Error during schema validation")
+        return
+    }
+    schema, err := compiler.Compile("inline")
+    if err != nil {
+        w.WriteHeader(http.StatusBadRequest)
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Schema validation failed", "errors": err.Error()})
+        logRequest(r, "Schema validation failed")
+        return
+    }
+
+    payloadSizeBytes.Observe(float64(len(body)))
+    start := time.Now()
+    err = schema.Validate(requestData.Data)
+    validationDuration.Observe(time.Since(start).Seconds())
+    writeValidationResult(w, r, level, "inline", requestData.Data, schema, err, false)
+}
+
+// bulkLineResult is one line of the NDJSON response stream produced by the
+// bulk validation endpoints.
+type bulkLineResult struct {
+    Line   int      `json:"line"`
+    Result string   `json:"result"`
+    Errors []string `json:"errors,omitempty"`
+}
+
+// runBulkValidation reads one JSON document per line from body and streams
+// a bulkLineResult per line back on w as soon as it's ready, flushing after
+// each line. validate is called concurrently across bulkWorkers workers
+// against the cached compiled schema; results are buffered just long enough
+// to write them back out in input order, without waiting for the whole
+// request body to be read first.
+func runBulkValidation(w http.ResponseWriter, r *http.Request, body io.Reader, validate func(interface{}) error) {
+    flusher, _ := w.(http.Flusher)
+    scanner := bufio.NewScanner(body)
+    scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+    type job struct {
+        line int
+        data []byte
+    }
+    jobs := make(chan job)
+    results := make(chan bulkLineResult)
+    var wg sync.WaitGroup
+
+    for i := 0; i < bulkWorkers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := range jobs {
+                var doc interface{}
+                result := bulkLineResult{Line: j.line}
+                if err := json.Unmarshal(j.data, &doc); err != nil {
+                    result.Result = "failed"
+                    result.Errors = []string{fmt.Sprintf("invalid JSON: %s", err.Error())}
+                } else if err := validate(doc); err != nil {
+                    result.Result = "failed"
+                    if ve, ok := err.(*jsonschema.ValidationError); ok {
+                        result.Errors = stripFilePathsFromErrors(ve.BasicOutput().Errors)
+                    } else {
+                        result.Errors = []string{err.Error()}
+                    }
+                } else {
+                    result.Result = "passed"
+                }
+                results <- result
+            }
+        }()
+    }
+
+    // lineNum and scanErr are written only by this goroutine, and only read
+    // back out (below) after results is drained to completion, which can't
+    // happen until this goroutine has closed jobs - so no separate lock is
+    // needed to hand them off safely.
+    var lineNum int
+    var scanErr error
+    go func() {
+        for lineNum < maxBulkSize && scanner.Scan() {
+            lineNum++
+            line := append([]byte(nil), scanner.Bytes()...)
+            jobs <- job{line: lineNum, data: line}
+        }
+        scanErr = scanner.Err()
+        close(jobs)
+    }()
+
+    go func() {
+        wg.Wait()
+        close(results)
+    }()
+
+    encoder := json.NewEncoder(w)
+    nextToWrite := 1
+    pending := make(map[int]bulkLineResult)
+    for result := range results {
+        pending[result.Line] = result
+        for {
+            ready, ok := pending[nextToWrite]
+            if !ok {
+                break
+            }
+            encoder.Encode(ready)
+            if flusher != nil {
+                flusher.Flush()
+            }
+            delete(pending, nextToWrite)
+            nextToWrite++
+        }
+    }
+
+    if scanErr != nil {
+        log.Printf("bulk validation: error reading NDJSON body after line %d: %v", lineNum, scanErr)
+        encoder.Encode(bulkLineResult{Line: lineNum + 1, Result: "error", Errors: []string{fmt.Sprintf("failed to read request body: %s", scanErr.Error())}})
+        if flusher != nil {
+            flusher.Flush()
+        }
+    }
+    logRequest(r, "Bulk validation completed")
+}
+
+func bulkValidateHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/x-ndjson")
+    vars := mux.Vars(r)
+    schemaName := normalizeSchemaName(vars["schema"])
+
+    schema, version, found, err := lookupSchema(defaultProject, schemaName, r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid version")
+        return
+    }
+    if !found {
+        http.Error(w, `{"error":"Schema not found"}`, http.StatusNotFound)
+        logRequest(r, "Schema not found")
+        return
+    }
+
+    if specParam := r.URL.Query().Get("spec"); specParam != "" {
+        schema, err = resolveStoredSchema(defaultProject, schemaName, version, specParam)
+        if err != nil {
+            http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+            logRequest(r, "Invalid spec")
+            return
+        }
+    }
+
+    runBulkValidation(w, r, r.Body, func(doc interface{}) error {
+        err := schema.Validate(doc)
+        recordValidation(r, schemaName, err == nil)
+        return err
+    })
+}
+
+// bulkValidateWithSchemaHandler expects the schema as the first NDJSON line
+// of the request body, followed by one document per subsequent line, so the
+// whole request remains a single streaming body like its sibling endpoint.
+func bulkValidateWithSchemaHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/x-ndjson")
+
+    spec, err := getSpec(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid spec")
+        return
+    }
+
+    reader := bufio.NewReader(r.Body)
+    schemaLine, err := reader.ReadBytes('\n')
+    if err != nil && err != io.EOF {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
+        return
+    }
+
+    compiler := jsonschema.NewCompiler()
+    compiler.Draft = spec
+    compiler.ExtractAnnotations = true
+    applyExtensions(compiler, keywordPatterns)
+    if err := compiler.AddResource("inline", strings.NewReader(string(schemaLine))); err != nil {
+        http.Error(w, `{"error":"Error during schema validation"}`, http.StatusInternalServerError)
+        logRequest(r, "Error during schema validation")
+        return
+    }
+    schema, err := compiler.Compile("inline")
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Schema validation failed")
+        return
+    }
+
+    runBulkValidation(w, r, reader, func(doc interface{}) error {
+        err := schema.Validate(doc)
+        recordValidation(r, "inline", err == nil)
+        return err
+    })
+}
+
+// openapiOperation pairs a loaded OpenAPI operation with the HTTP path and
+// method it was declared under, since openapi3.Operation itself does not
+// carry that context.
+type openapiOperation struct {
+    path      string
+    method    string
+    operation *openapi3.Operation
+}
+
+// openapiDoc is one loaded OpenAPI document indexed by operationId so
+// /validate/openapi/{doc}/{operationId} can find the right operation
+// without walking the whole path tree on every request.
+type openapiDoc struct {
+    doc        *openapi3.T
+    operations map[string]*openapiOperation
+}
+
+// loadOpenAPIDoc loads and validates a single OpenAPI document and indexes
+// its operations by operationId.
+func loadOpenAPIDoc(path string) (*openapiDoc, error) {
+    loader := openapi3.NewLoader()
+    doc, err := loader.LoadFromFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load OpenAPI document: %w", err)
+    }
+    if err := doc.Validate(loader.Context); err != nil {
+        return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+    }
+
+    operations := make(map[string]*openapiOperation)
+    // kin-openapi >=v0.113 made Paths a struct wrapping the path map (the
+    // same release that gave Responses its Value()/Default() accessors,
+    // which this file also relies on below), so the underlying map is
+    // reached via Map() rather than ranging over doc.Paths directly.
+    for path, pathItem := range doc.Paths.Map() {
+        for method, operation := range pathItem.Operations() {
+            if operation.OperationID == "" {
+                continue
+            }
+            operations[operation.OperationID] = &openapiOperation{path: path, method: method, operation: operation}
+        }
+    }
+    return &openapiDoc{doc: doc, operations: operations}, nil
+}
+
+// loadOpenAPIDocs loads every OpenAPI document in dir, keyed by its base
+// filename without extension (the {doc} path segment).
+func loadOpenAPIDocs(dir string) error {
+    if dir == "" {
+        return nil
+    }
+    files, err := ioutil.ReadDir(dir)
+    if err != nil {
+        return fmt.Errorf("failed to read OpenAPI directory: %w", err)
+    }
+    for _, file := range files {
+        ext := filepath.Ext(file.Name())
+        if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+            continue
+        }
+        path := filepath.Join(dir, file.Name())
+        name := strings.TrimSuffix(file.Name(), ext)
+        doc, err := loadOpenAPIDoc(path)
+        if err != nil {
+            log.Printf("Failed to load OpenAPI document %s: %v", path, err)
+            continue
+        }
+        openapiMutex.Lock()
+        openapiDocs[name] = doc
+        openapiMutex.Unlock()
+        log.Printf("Loaded OpenAPI document %s with %d operations", name, len(doc.operations))
+    }
+    return nil
+}
+
+// compileOpenAPISchema turns an OpenAPI schema fragment into a compiled
+// jsonschema.Schema so it can be validated with the same engine used
+// everywhere else in this server. id must not contain a "#": the jsonschema
+// library treats any "#" in a resource ID as a URI fragment separator and
+// panics building one (callers use "/"-joined path segments instead).
+func compileOpenAPISchema(id string, schemaRef *openapi3.SchemaRef) (*jsonschema.Schema, error) {
+    if schemaRef == nil || schemaRef.Value == nil {
+        return nil, nil
+    }
+    schemaBytes, err := json.Marshal(schemaRef.Value)
+    if err != nil {
+        return nil, fmt.Errorf("failed to convert OpenAPI schema to JSON Schema: %w", err)
+    }
+    compiler := jsonschema.NewCompiler()
+    compiler.Draft = jsonschema.Draft2020
+    applyExtensions(compiler, keywordPatterns)
+    if err := compiler.AddResource(id, strings.NewReader(string(schemaBytes))); err != nil {
+        return nil, err
+    }
+    return compiler.Compile(id)
+}
+
+// openapiEnvelope is the request shape for /validate/openapi/{doc}/{operationId}:
+// the caller supplies the request they would have sent and, optionally, the
+// response they received, and both are validated against the operation.
+type openapiEnvelope struct {
+    Request struct {
+        Headers map[string]interface{} `json:"headers"`
+        Query   map[string]interface{} `json:"query"`
+        Path    map[string]interface{} `json:"path"`
+        Body    interface{}            `json:"body"`
+    } `json:"request"`
+    Response struct {
+        Status int         `json:"status"`
+        Body    interface{} `json:"body"`
+    } `json:"response"`
+}
+
+// validateOpenAPIParams validates the given named parameters (path, query,
+// or header) against the operation's declared parameters of the matching
+// "in" location, returning one error message per violated parameter.
+func validateOpenAPIParams(params openapi3.Parameters, in string, values map[string]interface{}) []string {
+    var errors []string
+    for _, paramRef := range params {
+        param := paramRef.Value
+        if param == nil || param.In != in {
+            continue
+        }
+        value, present := values[param.Name]
+        if !present {
+            if param.Required {
+                errors = append(errors, fmt.Sprintf("%s parameter %q is required", in, param.Name))
+            }
+            continue
+        }
+        schema, err := compileOpenAPISchema(in+"/"+param.Name, param.Schema)
+        if err != nil {
+            errors = append(errors, fmt.Sprintf("%s parameter %q: %s", in, param.Name, err.Error()))
+            continue
+        }
+        if schema == nil {
+            continue
+        }
+        if err := schema.Validate(value); err != nil {
+            errors = append(errors, fmt.Sprintf("%s parameter %q: %s", in, param.Name, err.Error()))
+        }
+    }
+    return errors
+}
+
+func openapiValidateHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    vars := mux.Vars(r)
+    docName := vars["doc"]
+    operationID := vars["operationId"]
+
+    openapiMutex.RLock()
+    doc, found := openapiDocs[docName]
+    openapiMutex.RUnlock()
+    if !found {
+        http.Error(w, `{"error":"OpenAPI document not found"}`, http.StatusNotFound)
+        logRequest(r, "OpenAPI document not found")
+        return
+    }
+
+    op, found := doc.operations[operationID]
+    if !found {
+        http.Error(w, `{"error":"Operation not found"}`, http.StatusNotFound)
+        logRequest(r, "Operation not found")
+        return
+    }
+
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
+        return
+    }
+
+    var envelope openapiEnvelope
+    if err := json.Unmarshal(body, &envelope); err != nil {
+        http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid JSON")
+        return
+    }
+
+    var errors []string
+    errors = append(errors, validateOpenAPIParams(op.operation.Parameters, "path", envelope.Request.Path)...)
+    errors = append(errors, validateOpenAPIParams(op.operation.Parameters, "query", envelope.Request.Query)...)
+    errors = append(errors, validateOpenAPIParams(op.operation.Parameters, "header", envelope.Request.Headers)...)
+
+    if op.operation.RequestBody != nil && op.operation.RequestBody.Value != nil {
+        if media := op.operation.RequestBody.Value.Content.Get("application/json"); media != nil {
+            schema, err := compileOpenAPISchema(operationID+"/requestBody", media.Schema)
+            if err != nil {
+                errors = append(errors, fmt.Sprintf("request body: %s", err.Error()))
+            } else if schema != nil {
+                if err := schema.Validate(envelope.Request.Body); err != nil {
+                    errors = append(errors, fmt.Sprintf("request body: %s", err.Error()))
+                }
+            }
+        }
+    }
+
+    if envelope.Response.Status != 0 {
+        statusKey := fmt.Sprintf("%d", envelope.Response.Status)
+        response := op.operation.Responses.Value(statusKey)
+        if response == nil {
+            response = op.operation.Responses.Default()
+        }
+        if response != nil && response.Value != nil {
+            if media := response.Value.Content.Get("application/json"); media != nil {
+                schema, err := compileOpenAPISchema(operationID+"/response", media.Schema)
+                if err != nil {
+                    errors = append(errors, fmt.Sprintf("response body: %s", err.Error()))
+                } else if schema != nil {
+                    if err := schema.Validate(envelope.Response.Body); err != nil {
+                        errors = append(errors, fmt.Sprintf("response body: %s", err.Error()))
+                    }
+                }
+            }
+        } else {
+            errors = append(errors, fmt.Sprintf("no response declared for status %d", envelope.Response.Status))
+        }
+    }
+
+    schemaLabel := docName + "/" + operationID
+    if len(errors) > 0 {
+        recordValidation(r, schemaLabel, false)
+        w.WriteHeader(http.StatusBadRequest)
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Validation failed", "errors": errors})
+        logRequest(r, "Validation failed")
+        return
+    }
+
+    recordValidation(r, schemaLabel, true)
+    w.WriteHeader(http.StatusOK)
+    json.NewEncoder(w).Encode(map[string]string{"result": "Validation passed"})
+    logRequest(r, "Validation passed")
+}
+
+func updateStats(path string, passed bool) {
+    statsMutex.Lock()
+    defer statsMutex.Unlock()
+
+    if stats[path] == nil {
+        stats[path] = &PathStats{}
+    }
+
+    stats[path].Requests++
+    if passed {
+        stats[path].Passes++
+    } else {
+        stats[path].Fails++
+    }
+}
+
+// recordValidation updates both the legacy path-keyed stats map (backing
+// /stats) and the youvalidateme_validations_total Prometheus counter,
+// labeled by schema name, so the two stay in sync without callers having to
+// touch both.
+func recordValidation(r *http.Request, schemaName string, passed bool) {
+    updateStats(r.URL.Path, passed)
+    result := "passed"
+    if !passed {
+        result = "failed"
+    }
+    validationsTotal.WithLabelValues(schemaName, result).Inc()
+}
+
+// statsHandler retains the pre-Prometheus per-path JSON summary for backwards
+// compatibility; operators wanting richer, queryable metrics should scrape
+// /metrics instead, which is backed by the same validation/upload counters.
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    statsMutex.Lock()
+    defer statsMutex.Unlock()
+
+    jsonStats, err := json.Marshal(stats)
+    if err != nil {
+        http.Error(w, `{"error":"Error generating stats"}`, http.StatusInternalServerError)
+        logRequest(r, "Error generating stats")
+        return
+    }
+
+    w.Write(jsonStats)
+    logRequest(r, "Stats retrieved")
+}
+
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    vars := mux.Vars(r)
+    schemaName := normalizeSchemaName(vars["schema"])
+    project := requestProject(r)
+    if !checkProjectAccess(r, project) {
+        http.Error(w, `{"error":"Invalid or missing project token"}`, http.StatusForbidden)
+        logRequest(r, "Invalid or missing project token")
+        return
+    }
+
+    _, version, found, err := lookupSchema(project, schemaName, r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid version")
+        return
+    }
+    if !found {
+        http.Error(w, `{"error":"Schema not found"}`, http.StatusNotFound)
+        logRequest(r, "Schema not found")
+        return
+    }
+
+    if bundle, err := strconv.ParseBool(r.URL.Query().Get("bundle")); err == nil && bundle {
+        bundled, err := bundleSchema(project, schemaName, version)
+        if err != nil {
+            http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+            logRequest(r, "Failed to bundle schema")
+            return
+        }
+        json.NewEncoder(w).Encode(bundled)
+        logRequest(r, "Schema bundled")
+        return
+    }
+
+    path, err := findSchemaVersionPath(project, schemaName, version)
+    if err != nil {
+        http.Error(w, `{"error":"Failed to read schema file"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to read schema file")
+        return
+    }
+    schemaContent, err := ioutil.ReadFile(path)
+    if err != nil {
+        http.Error(w, `{"error":"Failed to read schema file"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to read schema file")
+        return
+    }
+
+    w.Write(schemaContent)
+    logRequest(r, "Schema retrieved")
+}
+
+// bundleSchema loads name's stored schema and rewrites every external $ref
+// it (transitively) contains into an inlined "#/$defs/<key>" pointer,
+// copying the referenced document into a top-level $defs object so the
+// result is a single self-contained artifact suitable for form generation
+// or offline validation. $refs that are already local ("#/...") are left
+// untouched.
+func bundleSchema(project, name string, version int) (map[string]interface{}, error) {
+    path, err := findSchemaVersionPath(project, name, version)
+    if err != nil {
+        return nil, err
+    }
+    content, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read schema file: %w", err)
+    }
+    format := formatFromExt(path)
+    var doc interface{}
+    if format == "json" {
+        if err := json.Unmarshal(content, &doc); err != nil {
+            return nil, fmt.Errorf("invalid schema JSON: %w", err)
+        }
+    } else {
+        if doc, err = decodeDocument(content, format); err != nil {
+            return nil, err
+        }
+    }
+
+    root, ok := doc.(map[string]interface{})
+    if !ok {
+        return nil, fmt.Errorf("schema %s is not a JSON object", name)
+    }
+
+    defs := map[string]interface{}{}
+    if existing, ok := root["$defs"].(map[string]interface{}); ok {
+        for k, v := range existing {
+            defs[k] = v
+        }
+    }
+    inlineRefs(root, path, defs, make(map[string]bool))
+    root["$defs"] = defs
+    return root, nil
+}
+
+// inlineRefs walks v looking for {"$ref": "<file>[#<pointer>]"} nodes whose
+// ref is not already local (doesn't start with "#"). Each referenced
+// document is loaded through refLoader (so it benefits from the same
+// project-directory fallback and caching used at validation time), added to
+// defs exactly once (seen prevents infinite recursion on circular refs),
+// and its own $refs are inlined in turn before the node's $ref is rewritten
+// to point at the inlined copy.
+func inlineRefs(v interface{}, basePath string, defs map[string]interface{}, seen map[string]bool) {
+    switch node := v.(type) {
+    case map[string]interface{}:
+        if ref, ok := node["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+            file, fragment := ref, ""
+            if idx := strings.Index(ref, "#"); idx >= 0 {
+                file, fragment = ref[:idx], ref[idx:]
+            }
+            key := defsKeyForFile(file)
+            refPath := file
+            if !strings.HasPrefix(file, "http://") && !strings.HasPrefix(file, "https://") {
+                refPath = filepath.Join(filepath.Dir(basePath), file)
+            }
+            if !seen[refPath] {
+                seen[refPath] = true
+                if loaded, err := refLoader.loadDocument(refPath); err == nil {
+                    if loadedMap, ok := loaded.(map[string]interface{}); ok {
+                        defs[key] = loadedMap
+                        inlineRefs(loadedMap, refPath, defs, seen)
+                    }
+                }
+            }
+            node["$ref"] = "#/$defs/" + key + strings.TrimPrefix(fragment, "#")
+            return
+        }
+        for _, child := range node {
+            inlineRefs(child, basePath, defs, seen)
+        }
+    case []interface{}:
+        for _, child := range node {
+            inlineRefs(child, basePath, defs, seen)
+        }
+    }
+}
+
+// defsKeyForFile derives a $defs key from a $ref's file component, e.g.
+// "global.json" becomes "global".
+func defsKeyForFile(file string) string {
+    base := filepath.Base(file)
+    return sanitizeFilename(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+// schemaVersionsHandler lists every published version number for a schema.
+func schemaVersionsHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    vars := mux.Vars(r)
+    schemaName := normalizeSchemaName(vars["schema"])
+    project := requestProject(r)
+    if !checkProjectAccess(r, project) {
+        http.Error(w, `{"error":"Invalid or missing project token"}`, http.StatusForbidden)
+        logRequest(r, "Invalid or missing project token")
+        return
+    }
+
+    versions, err := listSchemaVersions(project, schemaName)
+    if err != nil || len(versions) == 0 {
+        http.Error(w, `{"error":"Schema not found"}`, http.StatusNotFound)
+        logRequest(r, "Schema not found")
+        return
+    }
+
+    cacheMutex.RLock()
+    current := currentVersion[schemaKey(project, schemaName)]
+    cacheMutex.RUnlock()
+
+    json.NewEncoder(w).Encode(map[string]interface{}{"versions": versions, "current": current})
+    logRequest(r, "Schema versions retrieved")
+}
+
+// schemaVersionHandler retrieves one specific, explicitly numbered version.
+func schemaVersionHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    vars := mux.Vars(r)
+    schemaName := normalizeSchemaName(vars["schema"])
+    project := requestProject(r)
+    if !checkProjectAccess(r, project) {
+        http.Error(w, `{"error":"Invalid or missing project token"}`, http.StatusForbidden)
+        logRequest(r, "Invalid or missing project token")
+        return
+    }
+    version, err := strconv.Atoi(vars["version"])
+    if err != nil {
+        http.Error(w, `{"error":"Invalid version"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid version")
+        return
+    }
+
+    cacheMutex.RLock()
+    _, found := cache[schemaKey(project, schemaName)][version]
+    cacheMutex.RUnlock()
+    if !found {
+        http.Error(w, `{"error":"Schema version not found"}`, http.StatusNotFound)
+        logRequest(r, "Schema version not found")
+        return
+    }
+
+    path, err := findSchemaVersionPath(project, schemaName, version)
+    if err != nil {
+        http.Error(w, `{"error":"Failed to read schema file"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to read schema file")
+        return
+    }
+    schemaContent, err := ioutil.ReadFile(path)
+    if err != nil {
+        http.Error(w, `{"error":"Failed to read schema file"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to read schema file")
+        return
+    }
+
+    w.Write(schemaContent)
+    logRequest(r, "Schema version retrieved")
+}
+
+// schemaRollbackHandler republishes an existing older version as current by
+// atomically rewriting the manifest; it never rewrites version files.
+func schemaRollbackHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    if !allowSaveUploads {
+        http.Error(w, `{"error":"Schema rollback is disabled"}`, http.StatusForbidden)
+        logRequest(r, "Schema rollback is disabled")
+        return
+    }
+
+    vars := mux.Vars(r)
+    schemaName := normalizeSchemaName(vars["schema"])
+    project := requestProject(r)
+    if !checkProjectAccess(r, project) {
+        http.Error(w, `{"error":"Invalid or missing project token"}`, http.StatusForbidden)
+        logRequest(r, "Invalid or missing project token")
+        return
+    }
+    version, err := strconv.Atoi(vars["version"])
+    if err != nil {
+        http.Error(w, `{"error":"Invalid version"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid version")
+        return
+    }
+
+    cacheMutex.RLock()
+    _, found := cache[schemaKey(project, schemaName)][version]
+    cacheMutex.RUnlock()
+    if !found {
+        http.Error(w, `{"error":"Schema version not found"}`, http.StatusNotFound)
+        logRequest(r, "Schema version not found")
+        return
+    }
+
+    if err := writeSchemaManifest(project, schemaName, version); err != nil {
+        http.Error(w, `{"error":"Failed to update manifest"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to update manifest")
+        return
+    }
+
+    cacheMutex.Lock()
+    currentVersion[schemaKey(project, schemaName)] = version
+    cacheMutex.Unlock()
+
+    json.NewEncoder(w).Encode(map[string]interface{}{"result": "Rolled back", "current": version})
+    logRequest(r, "Schema rolled back")
+}
+
+func uploadSchemaHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    uploadResult := "error"
+    defer func() { uploadsTotal.WithLabelValues(uploadResult).Inc() }()
+    if !allowSaveUploads {
+        http.Error(w, `{"error":"Schema uploads are disabled"}`, http.StatusForbidden)
+        logRequest(r, "Schema uploads are disabled")
+        return
+    }
+
+    if r.ContentLength > maxUploadSize {
+        http.Error(w, `{"error":"Uploaded schema is too large"}`, http.StatusRequestEntityTooLarge)
+        logRequest(r, "Uploaded schema is too large")
+        return
+    }
+
+    docFormat, err := docFormatFromRequest(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid format")
+        return
+    }
+
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
+        return
+    }
+
+    schemaData, err := decodeDocument(body, docFormat)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid schema document")
+        return
+    }
+
+    vars := mux.Vars(r)
+    schemaName := normalizeSchemaName(vars["schema"])
+    project := requestProject(r)
+    if !checkProjectAccess(r, project) {
+        http.Error(w, `{"error":"Invalid or missing project token"}`, http.StatusForbidden)
+        logRequest(r, "Invalid or missing project token")
+        return
+    }
+    if project != defaultProject {
+        if _, err := safePath(projectsRootDir(), project); err != nil {
+            http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+            logRequest(r, err.Error())
+            return
+        }
+        if _, err := os.Stat(projectDir(project)); err != nil {
+            http.Error(w, `{"error":"Project not found"}`, http.StatusNotFound)
+            logRequest(r, "Project not found")
+            return
+        }
+    }
+
+    if _, err := safePath(projectDir(project), schemaName); err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, err.Error())
+        return
+    }
+
+    spec, err := getSpec(r)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, "Invalid spec")
+        return
+    }
+
+    schemaBytes, err := json.Marshal(schemaData)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid schema"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid schema")
+        return
+    }
+
+    compiler := newSchemaCompiler(spec)
+    uploadID := filepath.Join(schemaNameDir(project, schemaName), "upload.json")
+    if err := compiler.AddResource(uploadID, strings.NewReader(string(schemaBytes))); err != nil {
+        http.Error(w, `{"error":"Error during schema validation"}`, http.StatusInternalServerError)
+        logRequest(r, "Error during schema validation")
+        return
+    }
+    schema, err := compiler.Compile(uploadID)
+    if err != nil {
+        w.WriteHeader(http.StatusBadRequest)
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Validation failed", "errors": err.Error()})
+        logRequest(r, "Validation failed")
+        return
+    }
+
+    // Publish the schema as a new version, keeping it in its original syntax
+    // (JSON is pretty-printed for readability; YAML/HCL are stored as-is).
+    var content []byte
+    if docFormat == "json" {
+        pretty, err := json.MarshalIndent(schemaData, "", "  ")
+        if err != nil {
+            http.Error(w, `{"error":"Failed to pretty print schema"}`, http.StatusInternalServerError)
+            logRequest(r, "Failed to pretty print schema")
+            return
+        }
+        content = append(pretty, '\n')
+    } else {
+        content = body
+    }
+    ext := "json"
+    switch docFormat {
+    case "yaml":
+        ext = "yaml"
+    case "hcl":
+        ext = "hcl"
+    }
+
+    if err := os.MkdirAll(schemaNameDir(project, schemaName), 0755); err != nil {
+        http.Error(w, `{"error":"Failed to create schema directory"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to create schema directory")
+        return
+    }
+
+    versions, err := listSchemaVersions(project, schemaName)
+    if err != nil {
+        versions = nil
+    }
+    newVersion := 1
+    if len(versions) > 0 {
+        newVersion = versions[len(versions)-1] + 1
+    }
+
+    if err := writeSchemaVersion(project, schemaName, newVersion, ext, content); err != nil {
+        http.Error(w, `{"error":"Failed to save schema"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to save schema")
+        return
+    }
+    if err := writeSchemaManifest(project, schemaName, newVersion); err != nil {
+        http.Error(w, `{"error":"Failed to publish schema"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to publish schema")
+        return
+    }
+
+    // Update the cache
+    key := schemaKey(project, schemaName)
+    cacheMutex.Lock()
+    if cache[key] == nil {
+        cache[key] = make(map[int]*jsonschema.Schema)
+    }
+    cache[key][newVersion] = schema
+    currentVersion[key] = newVersion
+    cacheMutex.Unlock()
+
+    uploadResult = "success"
+    w.WriteHeader(http.StatusOK)
+    json.NewEncoder(w).Encode(map[string]interface{}{"result": "Schema uploaded and validated successfully", "version": newVersion})
+    logRequest(r, "Schema uploaded and validated successfully")
+}
+
+// draftParamAliases accepts the hyphenated draft names used by /infer's
+// ?draft= parameter (e.g. "draft-07") in addition to the short names
+// already used by ?spec= elsewhere (e.g. "draft7").
+var draftParamAliases = map[string]string{
+    "draft-04":      "draft4",
+    "draft04":       "draft4",
+    "draft-06":      "draft6",
+    "draft06":       "draft6",
+    "draft-07":      "draft7",
+    "draft07":       "draft7",
+    "2019-09":       "draft2019",
+    "draft-2019-09": "draft2019",
+    "2020-12":       "draft2020",
+    "draft-2020-12": "draft2020",
+}
+
+// draftSchemaURIs maps a validSpecs name to the "$schema" value /infer
+// stamps onto generated schemas.
+var draftSchemaURIs = map[string]string{
+    "draft4":    "http://json-schema.org/draft-04/schema#",
+    "draft6":    "http://json-schema.org/draft-06/schema#",
+    "draft7":    "http://json-schema.org/draft-07/schema#",
+    "draft2019": "https://json-schema.org/draft/2019-09/schema",
+    "draft2020": "https://json-schema.org/draft/2020-12/schema",
+}
+
+// inferredFormatPatterns maps optional string "format" hints to a regex a
+// sample value must match for inferSchema to annotate it.
+var inferredFormatPatterns = []struct {
+    format string
+    re     *regexp.Regexp
+}{
+    {"date-time", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}`)},
+    {"email", regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)},
+    {"uri", regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://\S+$`)},
+}
+
+// inferSchema generates a JSON Schema fragment describing a single sample
+// value: objects recurse into "properties" with every present key listed in
+// "required", arrays merge the inferred schema of every element into one
+// "items" schema, strings are checked against inferredFormatPatterns, and
+// numbers are reported as "integer" when they have no fractional part.
+func inferSchema(value interface{}) map[string]interface{} {
+    switch v := value.(type) {
+    case nil:
+        return map[string]interface{}{"type": "null"}
+    case bool:
+        return map[string]interface{}{"type": "boolean"}
+    case float64:
+        if v == math.Trunc(v) {
+            return map[string]interface{}{"type": "integer"}
+        }
+        return map[string]interface{}{"type": "number"}
+    case string:
+        schema := map[string]interface{}{"type": "string"}
+        for _, p := range inferredFormatPatterns {
+            if p.re.MatchString(v) {
+                schema["format"] = p.format
+                break
+            }
+        }
+        return schema
+    case []interface{}:
+        schema := map[string]interface{}{"type": "array"}
+        var items map[string]interface{}
+        for _, elem := range v {
+            items = mergeInferredSchemas(items, inferSchema(elem))
+        }
+        if items == nil {
+            items = map[string]interface{}{}
+        }
+        schema["items"] = items
+        return schema
+    case map[string]interface{}:
+        properties := make(map[string]interface{}, len(v))
+        required := make([]string, 0, len(v))
+        for key, child := range v {
+            properties[key] = inferSchema(child)
+            required = append(required, key)
+        }
+        sort.Strings(required)
+        return map[string]interface{}{
+            "type":       "object",
+            "properties": properties,
+            "required":   required,
+        }
+    default:
+        return map[string]interface{}{}
+    }
+}
+
+// mergeInferredSchemas unions two inferred schema fragments describing the
+// same field or array slot: distinct JSON types accumulate into a "type"
+// array, object properties merge key-by-key, and "required" keeps only keys
+// present on both sides so fields missing from some samples are dropped
+// from "required" but kept in "properties".
+func mergeInferredSchemas(a, b map[string]interface{}) map[string]interface{} {
+    if a == nil {
+        return b
+    }
+    if b == nil {
+        return a
+    }
+
+    merged := map[string]interface{}{"type": mergeInferredTypes(a["type"], b["type"])}
+
+    aProps, aIsObj := a["properties"].(map[string]interface{})
+    bProps, bIsObj := b["properties"].(map[string]interface{})
+    if aIsObj || bIsObj {
+        properties := make(map[string]interface{}, len(aProps)+len(bProps))
+        for key, schema := range aProps {
+            properties[key] = schema
+        }
+        for key, schema := range bProps {
+            if existing, ok := properties[key].(map[string]interface{}); ok {
+                properties[key] = mergeInferredSchemas(existing, schema.(map[string]interface{}))
+            } else {
+                properties[key] = schema
+            }
+        }
+        merged["properties"] = properties
+        merged["required"] = intersectRequired(requiredListOf(a), requiredListOf(b))
+    }
+
+    aItems, aIsArr := a["items"].(map[string]interface{})
+    bItems, bIsArr := b["items"].(map[string]interface{})
+    switch {
+    case aIsArr && bIsArr:
+        merged["items"] = mergeInferredSchemas(aItems, bItems)
+    case aIsArr:
+        merged["items"] = aItems
+    case bIsArr:
+        merged["items"] = bItems
+    }
+
+    if aFormat, ok := a["format"].(string); ok {
+        if bFormat, ok2 := b["format"].(string); ok2 && bFormat == aFormat {
+            merged["format"] = aFormat
+        }
+    }
+
+    return merged
+}
+
+func requiredListOf(schema map[string]interface{}) []string {
+    switch raw := schema["required"].(type) {
+    case []string:
+        return raw
+    case []interface{}:
+        out := make([]string, 0, len(raw))
+        for _, v := range raw {
+            if s, ok := v.(string); ok {
+                out = append(out, s)
+            }
+        }
+        return out
+    default:
+        return nil
+    }
+}
+
+func intersectRequired(a, b []string) []string {
+    bSet := make(map[string]bool, len(b))
+    for _, k := range b {
+        bSet[k] = true
+    }
+    out := make([]string, 0, len(a))
+    for _, k := range a {
+        if bSet[k] {
+            out = append(out, k)
+        }
+    }
+    sort.Strings(out)
+    return out
+}
+
+// mergeInferredTypes folds a's and b's "type" value (a string or a []string
+// from an earlier merge) into a sorted union, collapsing back to a plain
+// string when only one distinct type remains.
+func mergeInferredTypes(a, b interface{}) interface{} {
+    set := make(map[string]bool)
+    addType := func(t interface{}) {
+        switch val := t.(type) {
+        case string:
+            set[val] = true
+        case []string:
+            for _, s := range val {
+                set[s] = true
+            }
+        case []interface{}:
+            for _, v := range val {
+                if s, ok := v.(string); ok {
+                    set[s] = true
+                }
+            }
+        }
+    }
+    addType(a)
+    addType(b)
+
+    types := make([]string, 0, len(set))
+    for t := range set {
+        types = append(types, t)
+    }
+    sort.Strings(types)
+    if len(types) == 1 {
+        return types[0]
+    }
+    result := make([]interface{}, len(types))
+    for i, t := range types {
+        result[i] = t
+    }
+    return result
+}
+
+// inferHandler generates a JSON Schema from one or more sample documents.
+// With ?mode=samples the body is a top-level JSON array whose elements are
+// merged into a single schema; otherwise the body is a single document.
+// When --allow-save-uploads is set and ?name= is given, the generated schema
+// is also published as a new version of that stored schema.
+func inferHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+
+    draftParam := r.URL.Query().Get("draft")
+    if draftParam == "" {
+        draftParam = defaultSpec
+    }
+    specName := draftParam
+    if _, ok := validSpecs[specName]; !ok {
+        alias, ok := draftParamAliases[draftParam]
+        if !ok {
+            http.Error(w, fmt.Sprintf(`{"error":"invalid draft: %s"}`, draftParam), http.StatusBadRequest)
+            logRequest(r, "Invalid draft")
+            return
+        }
+        specName = alias
+    }
+
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
+        return
+    }
+
+    var inferred map[string]interface{}
+    if r.URL.Query().Get("mode") == "samples" {
+        var samples []interface{}
+        if err := json.Unmarshal(body, &samples); err != nil {
+            http.Error(w, `{"error":"Invalid JSON array of samples"}`, http.StatusBadRequest)
+            logRequest(r, "Invalid request body")
+            return
+        }
+        for _, sample := range samples {
+            inferred = mergeInferredSchemas(inferred, inferSchema(sample))
+        }
+        if inferred == nil {
+            inferred = map[string]interface{}{}
+        }
+    } else {
+        var doc interface{}
+        if err := json.Unmarshal(body, &doc); err != nil {
+            http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+            logRequest(r, "Invalid request body")
+            return
+        }
+        inferred = inferSchema(doc)
+    }
+
+    name := r.URL.Query().Get("name")
+    if name == "" {
+        name = "inferred"
+    }
+    inferred["$schema"] = draftSchemaURIs[specName]
+    inferred["$id"] = name + ".json"
+
+    stored := false
+    if allowSaveUploads && r.URL.Query().Get("name") != "" {
+        schemaName := normalizeSchemaName(name)
+        if _, err := safePath(schemasDir, schemaName); err != nil {
+            http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+            logRequest(r, err.Error())
+            return
+        }
+
+        schemaBytes, err := json.Marshal(inferred)
+        if err != nil {
+            http.Error(w, `{"error":"Invalid schema"}`, http.StatusBadRequest)
+            logRequest(r, "Invalid schema")
+            return
+        }
+
+        compiler := newSchemaCompiler(validSpecs[specName])
+        inferID := filepath.Join(schemaNameDir(defaultProject, schemaName), "infer.json")
+        if err := compiler.AddResource(inferID, strings.NewReader(string(schemaBytes))); err != nil {
+            http.Error(w, `{"error":"Error during schema validation"}`, http.StatusInternalServerError)
+            logRequest(r, "Error during schema validation")
+            return
+        }
+        compiled, err := compiler.Compile(inferID)
+        if err != nil {
+            w.WriteHeader(http.StatusBadRequest)
+            json.NewEncoder(w).Encode(map[string]interface{}{"result": "Schema validation failed", "errors": err.Error()})
+            logRequest(r, "Schema validation failed")
+            return
+        }
+
+        if err := os.MkdirAll(schemaNameDir(defaultProject, schemaName), 0755); err != nil {
+            http.Error(w, `{"error":"Failed to create schema directory"}`, http.StatusInternalServerError)
+            logRequest(r, "Failed to create schema directory")
+            return
+        }
+
+        versions, err := listSchemaVersions(defaultProject, schemaName)
+        if err != nil {
+            versions = nil
+        }
+        newVersion := 1
+        if len(versions) > 0 {
+            newVersion = versions[len(versions)-1] + 1
+        }
+
+        pretty, err := json.MarshalIndent(inferred, "", "  ")
+        if err != nil {
+            http.Error(w, `{"error":"Failed to pretty print schema"}`, http.StatusInternalServerError)
+            logRequest(r, "Failed to pretty print schema")
+            return
+        }
+        if err := writeSchemaVersion(defaultProject, schemaName, newVersion, "json", append(pretty, '\n')); err != nil {
+            http.Error(w, `{"error":"Failed to save schema"}`, http.StatusInternalServerError)
+            logRequest(r, "Failed to save schema")
+            return
+        }
+        if err := writeSchemaManifest(defaultProject, schemaName, newVersion); err != nil {
+            http.Error(w, `{"error":"Failed to publish schema"}`, http.StatusInternalServerError)
+            logRequest(r, "Failed to publish schema")
+            return
+        }
+
+        cacheMutex.Lock()
+        if cache[schemaName] == nil {
+            cache[schemaName] = make(map[int]*jsonschema.Schema)
+        }
+        cache[schemaName][newVersion] = compiled
+        currentVersion[schemaName] = newVersion
+        cacheMutex.Unlock()
+        stored = true
     }
 
-    updateStats(r.URL.Path, true)
     w.WriteHeader(http.StatusOK)
-    json.NewEncoder(w).Encode(map[string]string{"result": "Validation passed"})
-    logRequest(r, "Validation passed")
+    json.NewEncoder(w).Encode(map[string]interface{}{"schema": inferred, "stored": stored})
+    logRequest(r, "Schema inferred")
 }
 
-func validateWithSchemaHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "application/json")
+// protoSchemas holds compiled protobuf file descriptors, keyed by schema
+// name, for the POST /validate/protobuf/{name} endpoint. Unlike JSON
+// schemas, a stored protobuf schema is a single un-versioned .proto file
+// under schemasDir/protobuf, and it validates against the first message
+// type the file declares.
+var (
+    protoSchemas = make(map[string]*desc.FileDescriptor)
+    protoMutex   sync.RWMutex
+)
 
-    spec, err := getSpec(r)
-    if err != nil {
-        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
-        logRequest(r, "Invalid spec")
-        return
-    }
+func protoSchemasDir() string {
+    return filepath.Join(schemasDir, "protobuf")
+}
 
-    body, err := ioutil.ReadAll(r.Body)
+func protoSchemaPath(name string) string {
+    return filepath.Join(protoSchemasDir(), name+".proto")
+}
+
+// compileProtoFile parses name.proto from disk into a file descriptor using
+// protoparse, which implements the protobuf compiler's parsing and linking
+// rules in pure Go, so no protoc binary is required at runtime.
+func compileProtoFile(name string) (*desc.FileDescriptor, error) {
+    fileName := name + ".proto"
+    parser := protoparse.Parser{ImportPaths: []string{protoSchemasDir()}}
+    descriptors, err := parser.ParseFiles(fileName)
     if err != nil {
-        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
-        logRequest(r, "Invalid request body")
-        return
+        return nil, fmt.Errorf("invalid protobuf schema: %w", err)
     }
-
-    var requestData struct {
-        Data   interface{}            `json:"data"`
-        Schema map[string]interface{} `json:"schema"`
+    if len(descriptors) == 0 {
+        return nil, fmt.Errorf("no file descriptor produced for %s", fileName)
     }
+    return descriptors[0], nil
+}
 
-    if err := json.Unmarshal(body, &requestData); err != nil {
-        http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
-        logRequest(r, "Invalid JSON")
-        return
+// primaryMessage returns the first message type declared in a compiled
+// protobuf schema, the convention /validate/protobuf/{name} uses to pick
+// which message a payload is validated against.
+func primaryMessage(fd *desc.FileDescriptor) (*desc.MessageDescriptor, error) {
+    messages := fd.GetMessageTypes()
+    if len(messages) == 0 {
+        return nil, fmt.Errorf("protobuf schema declares no message types")
     }
+    return messages[0], nil
+}
 
-    schemaBytes, err := json.Marshal(requestData.Schema)
+func loadProtoSchemas() {
+    entries, err := ioutil.ReadDir(protoSchemasDir())
     if err != nil {
-        http.Error(w, `{"error":"Invalid schema"}`, http.StatusBadRequest)
-        logRequest(r, "Invalid schema")
+        if os.IsNotExist(err) {
+            return
+        }
+        log.Printf("Failed to read protobuf schemas directory: %v", err)
         return
     }
 
-    compiler := jsonschema.NewCompiler()
-    compiler.Draft = spec
-    compiler.ExtractAnnotations = true
-    if err := compiler.AddResource("inline", strings.NewReader(string(schemaBytes))); err != nil {
-        http.Error(w, `{"error":"Error during schema validation"}`, http.StatusInternalServerError)
-        logRequest(r, "Error during schema validation")
-        return
+    for _, entry := range entries {
+        if entry.IsDir() || filepath.Ext(entry.Name()) != ".proto" {
+            continue
+        }
+        name := strings.TrimSuffix(entry.Name(), ".proto")
+        fd, err := compileProtoFile(name)
+        if err != nil {
+            log.Printf("Failed to load protobuf schema %s: %v", name, err)
+            schemaLoadErrorsTotal.WithLabelValues(name).Inc()
+            continue
+        }
+        protoMutex.Lock()
+        protoSchemas[name] = fd
+        protoMutex.Unlock()
+        log.Printf("Loaded protobuf schema: %s", name)
     }
-    schema, err := compiler.Compile("inline")
-    if err != nil {
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Schema validation failed", "errors": err.Error()})
-        logRequest(r, "Schema validation failed")
-        return
+}
+
+// watchProtoSchemas mirrors watchSchemas for the protobuf schemas
+// directory: any create/write of a .proto file is recompiled and swapped
+// into protoSchemas so edits hot-reload without a restart.
+func watchProtoSchemas() error {
+    if err := os.MkdirAll(protoSchemasDir(), 0755); err != nil {
+        return fmt.Errorf("failed to create protobuf schemas directory: %v", err)
     }
 
-    err = schema.Validate(requestData.Data)
+    watcher, err := fsnotify.NewWatcher()
     if err != nil {
-        updateStats(r.URL.Path, false)
-        validationErrors := err.(*jsonschema.ValidationError).BasicOutput().Errors
-        errors := stripFilePathsFromErrors(validationErrors)
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Validation failed", "errors": errors})
-        logRequest(r, "Validation failed")
-        return
+        return fmt.Errorf("failed to create watcher: %v", err)
     }
+    defer watcher.Close()
 
-    updateStats(r.URL.Path, true)
-    w.WriteHeader(http.StatusOK)
-    json.NewEncoder(w).Encode(map[string]string{"result": "Validation passed"})
-    logRequest(r, "Validation passed")
-}
-
-func updateStats(path string, passed bool) {
-    statsMutex.Lock()
-    defer statsMutex.Unlock()
-
-    if stats[path] == nil {
-        stats[path] = &PathStats{}
+    if err := watcher.Add(protoSchemasDir()); err != nil {
+        return fmt.Errorf("failed to add directory to watcher: %v", err)
     }
 
-    stats[path].Requests++
-    if passed {
-        stats[path].Passes++
-    } else {
-        stats[path].Fails++
+    for {
+        select {
+        case event := <-watcher.Events:
+            if filepath.Ext(event.Name) != ".proto" {
+                continue
+            }
+            if event.Op&fsnotify.Write != fsnotify.Write && event.Op&fsnotify.Create != fsnotify.Create {
+                continue
+            }
+            name := strings.TrimSuffix(filepath.Base(event.Name), ".proto")
+            fd, err := compileProtoFile(name)
+            if err != nil {
+                log.Printf("Failed to reload protobuf schema %s: %v", name, err)
+                schemaLoadErrorsTotal.WithLabelValues(name).Inc()
+                continue
+            }
+            protoMutex.Lock()
+            protoSchemas[name] = fd
+            protoMutex.Unlock()
+            log.Printf("Reloaded protobuf schema: %s", name)
+        case err := <-watcher.Errors:
+            log.Println("Error watching protobuf schemas:", err)
+        }
     }
 }
 
-func statsHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "application/json")
-    statsMutex.Lock()
-    defer statsMutex.Unlock()
-
-    jsonStats, err := json.Marshal(stats)
-    if err != nil {
-        http.Error(w, `{"error":"Error generating stats"}`, http.StatusInternalServerError)
-        logRequest(r, "Error generating stats")
-        return
+// schemaByTypeHandler and uploadSchemaByTypeHandler generalize the original
+// JSON-only /schema/{schema} routes into a multi-format message-schema
+// registry: GET/POST /schema/{type}/{name} dispatches to the JSON or
+// protobuf store based on {type}, reusing the existing per-format handlers
+// by remapping mux vars rather than duplicating their logic.
+func schemaByTypeHandler(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    switch vars["type"] {
+    case "json":
+        schemaHandler(w, mux.SetURLVars(r, map[string]string{"schema": vars["name"]}))
+    case "protobuf":
+        protobufSchemaHandler(w, mux.SetURLVars(r, map[string]string{"schema": vars["name"]}))
+    default:
+        http.Error(w, fmt.Sprintf(`{"error":"unsupported schema type: %s"}`, vars["type"]), http.StatusBadRequest)
+        logRequest(r, "Unsupported schema type")
     }
-
-    w.Write(jsonStats)
-    logRequest(r, "Stats retrieved")
 }
 
-func schemaHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "application/json")
+func uploadSchemaByTypeHandler(w http.ResponseWriter, r *http.Request) {
     vars := mux.Vars(r)
-    schemaFile := vars["schema"]
-    if filepath.Ext(schemaFile) != ".json" {
-        schemaFile += ".json"
+    switch vars["type"] {
+    case "json":
+        uploadSchemaHandler(w, mux.SetURLVars(r, map[string]string{"schema": vars["name"]}))
+    case "protobuf":
+        protobufUploadHandler(w, mux.SetURLVars(r, map[string]string{"schema": vars["name"]}))
+    default:
+        http.Error(w, fmt.Sprintf(`{"error":"unsupported schema type: %s"}`, vars["type"]), http.StatusBadRequest)
+        logRequest(r, "Unsupported schema type")
     }
+}
 
-    cacheMutex.RLock()
-    _, found := cache[schemaFile]
-    cacheMutex.RUnlock()
-
-    if !found {
+// protobufSchemaHandler returns the stored .proto source for name.
+func protobufSchemaHandler(w http.ResponseWriter, r *http.Request) {
+    name := mux.Vars(r)["schema"]
+    content, err := ioutil.ReadFile(protoSchemaPath(name))
+    if err != nil {
         http.Error(w, `{"error":"Schema not found"}`, http.StatusNotFound)
         logRequest(r, "Schema not found")
         return
     }
-
-    schemaPath := filepath.Join(schemasDir, schemaFile)
-    schemaContent, err := ioutil.ReadFile(schemaPath)
-    if err != nil {
-        http.Error(w, `{"error":"Failed to read schema file"}`, http.StatusInternalServerError)
-        logRequest(r, "Failed to read schema file")
-        return
-    }
-
-    w.Write(schemaContent)
+    w.Header().Set("Content-Type", "text/plain")
+    w.Write(content)
     logRequest(r, "Schema retrieved")
 }
 
-func uploadSchemaHandler(w http.ResponseWriter, r *http.Request) {
+// protobufUploadHandler publishes a new .proto schema (only if
+// --allow-save-uploads is true), compiling it before it replaces whatever
+// was previously stored under name.
+func protobufUploadHandler(w http.ResponseWriter, r *http.Request) {
     w.Header().Set("Content-Type", "application/json")
+    uploadResult := "error"
+    defer func() { uploadsTotal.WithLabelValues(uploadResult).Inc() }()
     if !allowSaveUploads {
         http.Error(w, `{"error":"Schema uploads are disabled"}`, http.StatusForbidden)
         logRequest(r, "Schema uploads are disabled")
         return
     }
-
     if r.ContentLength > maxUploadSize {
         http.Error(w, `{"error":"Uploaded schema is too large"}`, http.StatusRequestEntityTooLarge)
         logRequest(r, "Uploaded schema is too large")
         return
     }
 
+    name := mux.Vars(r)["schema"]
+    if _, err := safePath(protoSchemasDir(), name+".proto"); err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, err.Error())
+        return
+    }
+
     body, err := ioutil.ReadAll(r.Body)
     if err != nil {
         http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
@@ -408,93 +2857,177 @@ func uploadSchemaHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    var schemaData interface{}
-    if err := json.Unmarshal(body, &schemaData); err != nil {
-        http.Error(w, `{"error":"Invalid JSON schema"}`, http.StatusBadRequest)
-        logRequest(r, "Invalid JSON schema")
+    if err := os.MkdirAll(protoSchemasDir(), 0755); err != nil {
+        http.Error(w, `{"error":"Failed to create schema directory"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to create schema directory")
         return
     }
 
-    vars := mux.Vars(r)
-    schemaFile := vars["schema"]
-    if filepath.Ext(schemaFile) != ".json" {
-        schemaFile += ".json"
+    tmpPath := protoSchemaPath(name) + ".tmp"
+    if err := ioutil.WriteFile(tmpPath, body, 0644); err != nil {
+        http.Error(w, `{"error":"Failed to save schema"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to save schema")
+        return
+    }
+    if err := os.Rename(tmpPath, protoSchemaPath(name)); err != nil {
+        http.Error(w, `{"error":"Failed to save schema"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to save schema")
+        return
     }
 
-    schemaPath, err := safePath(schemasDir, schemaFile)
+    fd, err := compileProtoFile(name)
     if err != nil {
-        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
-        logRequest(r, err.Error())
+        w.WriteHeader(http.StatusBadRequest)
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Schema validation failed", "errors": err.Error()})
+        logRequest(r, "Schema validation failed")
         return
     }
 
-    spec, err := getSpec(r)
-    if err != nil {
-        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
-        logRequest(r, "Invalid spec")
+    protoMutex.Lock()
+    protoSchemas[name] = fd
+    protoMutex.Unlock()
+
+    uploadResult = "success"
+    w.WriteHeader(http.StatusOK)
+    json.NewEncoder(w).Encode(map[string]interface{}{"result": "Schema uploaded and validated successfully"})
+    logRequest(r, "Schema uploaded and validated successfully")
+}
+
+// protobufValidateHandler validates a payload against the first message
+// type of a stored protobuf schema. A Content-Type of application/x-protobuf
+// is treated as a binary-encoded message; anything else is decoded as JSON
+// via protojson, which already reports unknown fields as part of its error.
+// Neither decoder is run with AllowPartial, so both already reject a message
+// missing a required field; the descriptor walk below repeats that check and
+// lists any missing required fields by name.
+//
+// The "required" keyword - and this check - only exists in proto2: proto3
+// dropped it, so field.Cardinality() == protoreflect.Required is never true
+// for a proto3 message. The check therefore only applies to proto2 schemas
+// (a file declaring `syntax = "proto2"`, or declaring no syntax at all); a
+// proto3 schema has no wire-level way to mark a field required, so every
+// field is effectively optional from this server's point of view.
+func protobufValidateHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    name := mux.Vars(r)["name"]
+    schemaLabel := "protobuf/" + name
+
+    protoMutex.RLock()
+    fd, found := protoSchemas[name]
+    protoMutex.RUnlock()
+    if !found {
+        http.Error(w, `{"error":"Schema not found"}`, http.StatusNotFound)
+        logRequest(r, "Schema not found")
         return
     }
 
-    schemaBytes, err := json.Marshal(schemaData)
+    msgDesc, err := primaryMessage(fd)
     if err != nil {
-        http.Error(w, `{"error":"Invalid schema"}`, http.StatusBadRequest)
-        logRequest(r, "Invalid schema")
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+        logRequest(r, "Invalid protobuf schema")
         return
     }
 
-    compiler := jsonschema.NewCompiler()
-    compiler.Draft = spec
-    compiler.ExtractAnnotations = true
-    if err := compiler.AddResource("uploaded", strings.NewReader(string(schemaBytes))); err != nil {
-        http.Error(w, `{"error":"Error during schema validation"}`, http.StatusInternalServerError)
-        logRequest(r, "Error during schema validation")
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+        logRequest(r, "Invalid request body")
         return
     }
-    schema, err := compiler.Compile("uploaded")
+    payloadSizeBytes.Observe(float64(len(body)))
+
+    message := dynamicpb.NewMessage(msgDesc.UnwrapMessage())
+    if r.Header.Get("Content-Type") == "application/x-protobuf" {
+        err = proto.Unmarshal(body, message)
+    } else {
+        err = protojson.Unmarshal(body, message)
+    }
     if err != nil {
+        recordValidation(r, schemaLabel, false)
         w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Validation failed", "errors": err.Error()})
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Validation failed", "errors": []string{err.Error()}})
         logRequest(r, "Validation failed")
         return
     }
 
-    // Save the schema to disk
-    prettySchema, err := json.MarshalIndent(schemaData, "", "  ")
-    if err != nil {
-        http.Error(w, `{"error":"Failed to pretty print schema"}`, http.StatusInternalServerError)
-        logRequest(r, "Failed to pretty print schema")
-        return
+    var missing []string
+    if !fd.IsProto3() {
+        fields := msgDesc.UnwrapMessage().Fields()
+        for i := 0; i < fields.Len(); i++ {
+            field := fields.Get(i)
+            if field.Cardinality() == protoreflect.Required && !message.Has(field) {
+                missing = append(missing, string(field.Name()))
+            }
+        }
     }
-    err = ioutil.WriteFile(schemaPath, append(prettySchema, '\n'), 0644)
-    if err != nil {
-        http.Error(w, `{"error":"Failed to save schema"}`, http.StatusInternalServerError)
-        logRequest(r, "Failed to save schema")
+    if len(missing) > 0 {
+        recordValidation(r, schemaLabel, false)
+        w.WriteHeader(http.StatusBadRequest)
+        errors := []string{fmt.Sprintf("missing required fields: %s", strings.Join(missing, ", "))}
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": "Validation failed", "errors": errors})
+        logRequest(r, "Validation failed")
         return
     }
 
-    // Update the cache
-    cacheMutex.Lock()
-    cache[schemaFile] = schema
-    cacheMutex.Unlock()
-
+    recordValidation(r, schemaLabel, true)
     w.WriteHeader(http.StatusOK)
-    json.NewEncoder(w).Encode(map[string]string{"result": "Schema uploaded and validated successfully"})
-    logRequest(r, "Schema uploaded and validated successfully")
+    json.NewEncoder(w).Encode(map[string]string{"result": "Validation passed"})
+    logRequest(r, "Validation passed")
+}
+
+// reservedSchemaDirNames are subdirectories of a project's schema directory
+// that this server itself creates for bookkeeping (the protobuf schema
+// store, and the namespaced-project store nested under the default
+// project), not a JSON schema an operator published - they're excluded from
+// listSchemasHandler's output.
+func reservedSchemaDirNames() map[string]bool {
+    return map[string]bool{
+        filepath.Base(protoSchemasDir()): true,
+        filepath.Base(projectsRootDir()): true,
+    }
 }
 
+// listSchemasHandler lists schema names for project, optionally filtered by
+// ?type= ("json", the default, or "protobuf").
 func listSchemasHandler(w http.ResponseWriter, r *http.Request) {
-    files, err := ioutil.ReadDir(schemasDir)
-    if err != nil {
-        http.Error(w, `{"error":"Failed to read schemas directory"}`, http.StatusInternalServerError)
-        logRequest(r, "Failed to read schemas directory")
+    project := requestProject(r)
+    if !checkProjectAccess(r, project) {
+        http.Error(w, `{"error":"Invalid or missing project token"}`, http.StatusForbidden)
+        logRequest(r, "Invalid or missing project token")
         return
     }
 
+    schemaType := r.URL.Query().Get("type")
+    if schemaType == "" {
+        schemaType = "json"
+    }
+
     schemaFiles := []string{}
-    for _, file := range files {
-        if filepath.Ext(file.Name()) == ".json" {
-            schemaFiles = append(schemaFiles, file.Name())
+    switch schemaType {
+    case "json":
+        files, err := ioutil.ReadDir(projectDir(project))
+        if err != nil {
+            http.Error(w, `{"error":"Failed to read schemas directory"}`, http.StatusInternalServerError)
+            logRequest(r, "Failed to read schemas directory")
+            return
+        }
+        reserved := reservedSchemaDirNames()
+        for _, file := range files {
+            if file.IsDir() && !reserved[file.Name()] {
+                schemaFiles = append(schemaFiles, file.Name())
+            }
         }
+    case "protobuf":
+        protoMutex.RLock()
+        for name := range protoSchemas {
+            schemaFiles = append(schemaFiles, name)
+        }
+        protoMutex.RUnlock()
+        sort.Strings(schemaFiles)
+    default:
+        http.Error(w, fmt.Sprintf(`{"error":"invalid type: %s"}`, schemaType), http.StatusBadRequest)
+        logRequest(r, "Invalid type")
+        return
     }
 
     format := r.URL.Query().Get("format")
@@ -530,6 +3063,65 @@ func listSchemasHandler(w http.ResponseWriter, r *http.Request) {
     }
 }
 
+// listProjectsHandler lists every known project: the implicit default
+// project plus every namespaced project directory under projectsRootDir.
+func listProjectsHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    projects := []string{defaultProject}
+    entries, err := ioutil.ReadDir(projectsRootDir())
+    if err != nil && !os.IsNotExist(err) {
+        http.Error(w, `{"error":"Failed to read projects directory"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to read projects directory")
+        return
+    }
+    for _, entry := range entries {
+        if entry.IsDir() {
+            projects = append(projects, entry.Name())
+        }
+    }
+    json.NewEncoder(w).Encode(map[string]interface{}{"projects": projects})
+    logRequest(r, "Projects listed")
+}
+
+// createProjectHandler provisions a new project's schema directory. Like
+// schema uploads, this is gated by --allow-save-uploads since it writes to
+// disk; an operator who wants the server read-only disables both together.
+func createProjectHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    if !allowSaveUploads {
+        http.Error(w, `{"error":"Project creation is disabled"}`, http.StatusForbidden)
+        logRequest(r, "Project creation is disabled")
+        return
+    }
+
+    project := mux.Vars(r)["project"]
+    if project == defaultProject {
+        http.Error(w, `{"error":"Project already exists"}`, http.StatusConflict)
+        logRequest(r, "Project already exists")
+        return
+    }
+    path, err := safePath(projectsRootDir(), project)
+    if err != nil {
+        http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+        logRequest(r, err.Error())
+        return
+    }
+    if _, err := os.Stat(path); err == nil {
+        http.Error(w, `{"error":"Project already exists"}`, http.StatusConflict)
+        logRequest(r, "Project already exists")
+        return
+    }
+    if err := os.MkdirAll(path, 0755); err != nil {
+        http.Error(w, `{"error":"Failed to create project directory"}`, http.StatusInternalServerError)
+        logRequest(r, "Failed to create project directory")
+        return
+    }
+
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(map[string]interface{}{"result": "Project created", "project": project})
+    logRequest(r, "Project created")
+}
+
 func checkSchemasDirWritable() error {
     testFile := filepath.Join(schemasDir, "test_write")
     if err := ioutil.WriteFile(testFile, []byte("test"), 0644); err != nil {
@@ -584,6 +3176,13 @@ func main() {
     log.Printf("Default Spec: %s", defaultSpec)
     log.Printf("Max Upload Size: %d MB", maxUploadSizeMB)
     log.Printf("Default Output Level: %s", defaultOutputLevel)
+    log.Printf("Extensions Directory: %s", extensionsDir)
+    log.Printf("Bulk Workers: %d", bulkWorkers)
+    log.Printf("Max Bulk Size: %d lines", maxBulkSize)
+    log.Printf("OpenAPI Directory: %s", openapiDir)
+    log.Printf("Default Clean Nulls: %t", defaultCleanNulls)
+    log.Printf("Project Tokens File: %s", projectTokensFile)
+    log.Printf("Allow Remote Refs: %t", allowRemoteRefs)
 
     // Get the current working directory
     workingDir, err = os.Getwd()
@@ -602,9 +3201,24 @@ func main() {
         }
     }
 
+    // Load registered custom keywords/formats before any schema is compiled
+    if err := loadExtensions(extensionsDir); err != nil {
+        log.Fatalf("Failed to load extensions: %v", err)
+    }
+
+    // Load per-project access tokens, if configured
+    if err := loadProjectTokens(projectTokensFile); err != nil {
+        log.Fatalf("Failed to load project tokens: %v", err)
+    }
+
     // Load initial schemas
     loadSchemas()
 
+    // Load OpenAPI documents for request/response validation, if configured
+    if err := loadOpenAPIDocs(openapiDir); err != nil {
+        log.Fatalf("Failed to load OpenAPI documents: %v", err)
+    }
+
     // Start watching for schema changes
     go func() {
         if err := watchSchemas(); err != nil {
@@ -612,13 +3226,42 @@ func main() {
         }
     }()
 
+    // Load protobuf schemas and watch for changes, mirroring the JSON schema setup above
+    loadProtoSchemas()
+    go func() {
+        if err := watchProtoSchemas(); err != nil {
+            log.Fatalf("Error watching protobuf schemas: %v", err)
+        }
+    }()
+
     r := mux.NewRouter()
     r.HandleFunc("/validate/{schema}", validateHandler).Methods("POST")
+    r.HandleFunc("/validate/{schema}/bulk", bulkValidateHandler).Methods("POST")
     r.HandleFunc("/validatewithschema", validateWithSchemaHandler).Methods("POST")
+    r.HandleFunc("/validatewithschema/bulk", bulkValidateWithSchemaHandler).Methods("POST")
+    r.HandleFunc("/validate/openapi/{doc}/{operationId}", openapiValidateHandler).Methods("POST")
+    r.HandleFunc("/validate/protobuf/{name}", protobufValidateHandler).Methods("POST")
     r.HandleFunc("/stats", statsHandler).Methods("GET")
+    r.Handle("/metrics", promhttp.Handler()).Methods("GET")
+    r.HandleFunc("/schema/{schema}/versions", schemaVersionsHandler).Methods("GET")
+    r.HandleFunc("/schema/{schema}/v{version:[0-9]+}", schemaVersionHandler).Methods("GET")
+    r.HandleFunc("/schema/{schema}/rollback/{version:[0-9]+}", schemaRollbackHandler).Methods("POST")
     r.HandleFunc("/schema/{schema}", schemaHandler).Methods("GET")
     r.HandleFunc("/schema/{schema}", uploadSchemaHandler).Methods("POST")
+    r.HandleFunc("/schema/{type}/{name}", schemaByTypeHandler).Methods("GET")
+    r.HandleFunc("/schema/{type}/{name}", uploadSchemaByTypeHandler).Methods("POST")
     r.HandleFunc("/schemas", listSchemasHandler).Methods("GET")
+    r.HandleFunc("/infer", inferHandler).Methods("POST")
+    r.HandleFunc("/extensions", extensionsHandler).Methods("GET")
+    r.HandleFunc("/projects", listProjectsHandler).Methods("GET")
+    r.HandleFunc("/projects/{project}", createProjectHandler).Methods("POST")
+    r.HandleFunc("/projects/{project}/validate/{schema}", validateHandler).Methods("POST")
+    r.HandleFunc("/projects/{project}/schemas/{schema}/versions", schemaVersionsHandler).Methods("GET")
+    r.HandleFunc("/projects/{project}/schemas/{schema}/v{version:[0-9]+}", schemaVersionHandler).Methods("GET")
+    r.HandleFunc("/projects/{project}/schemas/{schema}/rollback/{version:[0-9]+}", schemaRollbackHandler).Methods("POST")
+    r.HandleFunc("/projects/{project}/schemas/{schema}", schemaHandler).Methods("GET")
+    r.HandleFunc("/projects/{project}/schemas/{schema}", uploadSchemaHandler).Methods("POST")
+    r.HandleFunc("/projects/{project}/schemas", listSchemasHandler).Methods("GET")
 
     addr := fmt.Sprintf("%s:%d", hostname, port)
     log.Printf("Starting server on %s\n", addr)
@@ -635,6 +3278,17 @@ func printHelp() {
     fmt.Println("4. Retrieving a schema.")
     fmt.Println("5. Uploading a new schema (if allowed).")
     fmt.Println("6. Listing all schemas in the directory.")
+    fmt.Println("7. Listing custom validation keywords/formats registered via --extensions-dir.")
+    fmt.Println("8. Bulk validating NDJSON streams of documents against a schema.")
+    fmt.Println("9. Accepting schemas and documents in JSON, YAML, or HCL (via Content-Type or ?format=).")
+    fmt.Println("10. Validating request/response envelopes against OpenAPI 3.x operations loaded from --openapi-dir.")
+    fmt.Println("11. Exposing Prometheus metrics at /metrics for validations, uploads, schema load errors, and latency.")
+    fmt.Println("12. Stripping null-valued keys from documents before validation via ?clean=true or --clean-nulls.")
+    fmt.Println("13. Autodetecting a stored schema's draft from its $schema keyword, honoring ?spec= as a fallback override.")
+    fmt.Println("14. Inferring a JSON Schema from one or more example documents via POST /infer.")
+    fmt.Println("15. Validating protobuf-encoded or protojson payloads against stored .proto schemas via POST /validate/protobuf/{name}.")
+    fmt.Println("16. Namespacing schemas per project/tenant under /projects/{project}/schemas/{name} and /projects/{project}/validate/{name}, with the legacy unscoped routes acting as a \"default\" project. Per-project access can be restricted with --project-tokens-file.")
+    fmt.Println("17. Resolving $ref between stored schemas (e.g. a shared global.json), and bundling a schema with all local $refs inlined into $defs via GET /schema/{name}?bundle=true. Remote http(s) $refs are only followed if --allow-remote-refs is set.")
     fmt.Println("By default, schema uploads are disabled. You can enable schema uploads using the --allow-save-uploads flag.")
     fmt.Println("Uploads are limited in size to prevent excessively large schemas from being uploaded.")
     fmt.Println("For the validate and get schema operations, the schema file must have a .json extension and be located in the specified schemas directory.")
@@ -668,6 +3322,7 @@ Endpoints:
     Example: curl -X POST -d '{"your":"data"}' http://localhost:8080/validate/your_custom_schema_filename.json
     Example with spec query parameter: curl -X POST -d '{"your":"data"}' "http://localhost:8080/validate/your_custom_schema_filename.json?spec=draft7"
     Example with outputlevel query parameter: curl -X POST -d '{"your":"data"}' "http://localhost:8080/validate/your_custom_schema_filename.json?outputlevel=verbose"
+    Example with clean query parameter: curl -X POST -d '{"your":"data","extra":null}' "http://localhost:8080/validate/your_custom_schema_filename.json?clean=true"
 
   POST /validatewithschema - Validate JSON data against an inline schema.
     Example: curl -X POST -d '{"data":{"your":"data"},"schema":{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","properties":{"your":{"type":"string"}}}}' http://localhost:8080/validatewithschema
@@ -677,16 +3332,49 @@ Endpoints:
   GET /stats - Retrieve statistics on inbound paths and JSON schema validation passes/fails.
     Example: curl http://localhost:8080/stats
 
-  GET /schema/{schema} - Retrieve the specified schema.
-    Example: curl http://localhost:8080/schema/your_custom_schema_filename.json
+  GET /metrics - Prometheus-format metrics for validations, uploads, schema load errors, and validation latency/payload size.
+    Example: curl http://localhost:8080/metrics
+
+  GET /schema/{schema} - Retrieve the current version of the specified schema.
+    Example: curl http://localhost:8080/schema/your_custom_schema
+    Example with version query parameter: curl "http://localhost:8080/schema/your_custom_schema?version=2"
+
+  POST /schema/{schema} - Upload a new JSON schema, publishing it as the next version (only if --allow-save-uploads is true).
+    Example: curl -X POST -d '{"$schema":"http://json-schema.org/draft-07/schema#","title":"Example","type":"object","properties":{"example":{"type":"string"}}}' http://localhost:8080/schema/your_custom_schema
+    Example with spec query parameter: curl -X POST -d '{"$schema":"http://json-schema.org/draft-07/schema#","title":"Example","type":"object","properties":{"example":{"type":"string"}}}' "http://localhost:8080/schema/your_custom_schema?spec=draft6"
+    Example with outputlevel query parameter: curl -X POST -d '{"$schema":"http://json-schema.org/draft-07/schema#","title":"Example","type":"object","properties":{"example":{"type":"string"}}}' "http://localhost:8080/schema/your_custom_schema?outputlevel=flag"
 
-  POST /schema/{schema} - Upload a new JSON schema (only if --allow-save-uploads is true).
-    Example: curl -X POST -d '{"$schema":"http://json-schema.org/draft-07/schema#","title":"Example","type":"object","properties":{"example":{"type":"string"}}}' http://localhost:8080/schema/your_custom_schema_filename.json
-    Example with spec query parameter: curl -X POST -d '{"$schema":"http://json-schema.org/draft-07/schema#","title":"Example","type":"object","properties":{"example":{"type":"string"}}}' "http://localhost:8080/schema/your_custom_schema_filename.json?spec=draft6"
-    Example with outputlevel query parameter: curl -X POST -d '{"$schema":"http://json-schema.org/draft-07/schema#","title":"Example","type":"object","properties":{"example":{"type":"string"}}}' "http://localhost:8080/schema/your_custom_schema_filename.json?outputlevel=flag"
+  GET /schema/{schema}?bundle=true - Retrieve the schema with every local $ref inlined/dereferenced into $defs.
+    Example: curl "http://localhost:8080/schema/your_custom_schema?bundle=true"
+
+  GET /schema/{schema}/versions - List every published version number for a schema.
+    Example: curl http://localhost:8080/schema/your_custom_schema/versions
+
+  GET /schema/{schema}/v{n} - Retrieve one specific version of a schema.
+    Example: curl http://localhost:8080/schema/your_custom_schema/v2
+
+  POST /schema/{schema}/rollback/{n} - Make an existing older version current again (only if --allow-save-uploads is true).
+    Example: curl -X POST http://localhost:8080/schema/your_custom_schema/rollback/1
 
   GET /schemas - List all JSON schemas in the schemas directory.
     Example: curl http://localhost:8080/schemas
     Example (JSON format): curl http://localhost:8080/schemas?format=json
+    Example (protobuf schemas): curl http://localhost:8080/schemas?type=protobuf
+
+  POST /infer - Generate a JSON Schema from one or more example documents.
+    Example: curl -X POST -d '{"name":"Ada","age":36}' "http://localhost:8080/infer?name=person&draft=draft-07"
+    Example with multiple samples: curl -X POST -d '[{"name":"Ada"},{"name":"Bob","age":40}]' "http://localhost:8080/infer?mode=samples"
+
+  GET /extensions - List custom keywords/formats registered from --extensions-dir.
+    Example: curl http://localhost:8080/extensions
+
+  POST /validate/{schema}/bulk - Validate a stream of NDJSON documents (one per line) against a schema.
+    Example: curl -X POST --data-binary @documents.ndjson http://localhost:8080/validate/your_custom_schema_filename.json/bulk
+
+  POST /validatewithschema/bulk - Validate a stream of NDJSON documents against an inline schema given as the first line.
+    Example: (schema_line; cat documents.ndjson) | curl -X POST --data-binary @- http://localhost:8080/validatewithschema/bulk
+
+  POST /validate/openapi/{doc}/{operationId} - Validate a request/response envelope against an OpenAPI operation (requires --openapi-dir).
+    Example: curl -X POST -d '{"request":{"path":{"id":"123"},"body":{"name":"Widget"}}}' http://localhost:8080/validate/openapi/petstore/getPet
 `)
 }
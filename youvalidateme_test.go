@@ -0,0 +1,116 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/getkin/kin-openapi/openapi3"
+    "github.com/gorilla/mux"
+)
+
+// TestValidateOpenAPIParamsNoPanic guards against a resource ID containing
+// "#" being passed to jsonschema.Compiler.AddResource: the library treats
+// any "#" in an ID as a URI fragment separator and panics building one,
+// which crashed every real /validate/openapi/{doc}/{operationId} request
+// that supplied a path/query/header parameter.
+func TestValidateOpenAPIParamsNoPanic(t *testing.T) {
+    params := openapi3.Parameters{
+        &openapi3.ParameterRef{
+            Value: &openapi3.Parameter{
+                Name:   "id",
+                In:     "path",
+                Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{}},
+            },
+        },
+    }
+
+    errs := validateOpenAPIParams(params, "path", map[string]interface{}{"id": "abc"})
+    if len(errs) != 0 {
+        t.Fatalf("expected no validation errors, got %v", errs)
+    }
+}
+
+// TestOpenapiValidateHandlerParamsAndBodiesNoPanic POSTs a full envelope
+// (path and query parameters, a request body, and a response body)
+// through openapiValidateHandler itself. validateOpenAPIParams alone isn't
+// enough: the panic this guards against came from compileOpenAPISchema's
+// resource ID, and the handler builds those IDs for the request body and
+// response body independently of validateOpenAPIParams, so only driving the
+// real handler proves every call site is safe.
+func TestOpenapiValidateHandlerParamsAndBodiesNoPanic(t *testing.T) {
+    op := &openapi3.Operation{
+        OperationID: "createThing",
+        Parameters: openapi3.Parameters{
+            &openapi3.ParameterRef{Value: &openapi3.Parameter{
+                Name: "id", In: "path", Required: true,
+                Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}},
+            }},
+            &openapi3.ParameterRef{Value: &openapi3.Parameter{
+                Name: "q", In: "query",
+                Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}},
+            }},
+        },
+        RequestBody: &openapi3.RequestBodyRef{Value: &openapi3.RequestBody{
+            Content: openapi3.Content{
+                "application/json": &openapi3.MediaType{
+                    Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{
+                        Type:     "object",
+                        Required: []string{"name"},
+                        Properties: openapi3.Schemas{
+                            "name": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}},
+                        },
+                    }},
+                },
+            },
+        }},
+        Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: &openapi3.Response{
+            Content: openapi3.Content{
+                "application/json": &openapi3.MediaType{
+                    Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{
+                        Type: "object",
+                        Properties: openapi3.Schemas{
+                            "ok": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "boolean"}},
+                        },
+                    }},
+                },
+            },
+        }})),
+    }
+
+    openapiMutex.Lock()
+    openapiDocs["testdoc"] = &openapiDoc{
+        operations: map[string]*openapiOperation{
+            "createThing": {path: "/things/{id}", method: "POST", operation: op},
+        },
+    }
+    openapiMutex.Unlock()
+    defer func() {
+        openapiMutex.Lock()
+        delete(openapiDocs, "testdoc")
+        openapiMutex.Unlock()
+    }()
+
+    envelope := openapiEnvelope{}
+    envelope.Request.Path = map[string]interface{}{"id": "abc"}
+    envelope.Request.Query = map[string]interface{}{"q": "hi"}
+    envelope.Request.Body = map[string]interface{}{"name": "widget"}
+    envelope.Response.Status = 200
+    envelope.Response.Body = map[string]interface{}{"ok": true}
+    body, err := json.Marshal(envelope)
+    if err != nil {
+        t.Fatalf("failed to marshal envelope: %v", err)
+    }
+
+    req := httptest.NewRequest(http.MethodPost, "/validate/openapi/testdoc/createThing", bytes.NewReader(body))
+    req = mux.SetURLVars(req, map[string]string{"doc": "testdoc", "operationId": "createThing"})
+    rec := httptest.NewRecorder()
+
+    openapiValidateHandler(rec, req)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+    }
+}